@@ -1,3 +1,5 @@
+import type { ExplicitPreference, QualityLevel, ReleaseType, TagProfile } from "./response";
+
 export interface RequestUserLogin {
 	username: string;
 	password: string;
@@ -9,6 +11,29 @@ export interface RequestUser {
 	hiRes: boolean;
 }
 
+export interface RequestQualityPolicy {
+	preferences: QualityLevel[];
+	minimum: QualityLevel;
+	fallback: boolean;
+}
+
+export interface RequestUpgrade {
+	ids: number[];
+}
+
+export interface RequestAutoFetch {
+	schedule: string;
+}
+
+export interface RequestFollowFilters {
+	releaseTypes: ReleaseType[];
+	skipSinglesOnAlbum: boolean;
+	explicit: ExplicitPreference;
+	includePatterns: string[];
+	excludePatterns: string[];
+	minimumQuality: QualityLevel | "";
+}
+
 export interface RequestAdmin {
 	password: string;
 }
@@ -47,4 +72,108 @@ export interface RequestEditSong {
 	albumPeak: number;
 	trackGain: number;
 	trackPeak: number;
+	genres: string[];
+	label: string;
+	copyright: string;
+	barcode: string;
+	composers: string[];
+	lyricists: string[];
+	bpm: number;
+	key: string;
+	trackTotal: number;
+	discTotal: number;
+}
+
+export interface RequestSettings {
+	downloadGlobalLimit?: number;
+	downloadUserLimit?: number;
+	downloadRetryLimit?: number;
+	downloadRetryDelay?: number;
+	autoFetchSchedule?: string;
+	cacheSongTTL?: number;
+	cacheAlbumTTL?: number;
+	cacheArtistTTL?: number;
+	cachePlaylistTTL?: number;
+	coverMaxSize?: number;
+	coverFolderArt?: boolean;
+	coverArtistArt?: boolean;
+	trashRetentionDays?: number;
+}
+
+export interface RequestFetchRelease {
+	provider: string;
+	id: string;
+}
+
+export interface RequestFetchReleases {
+	releases: RequestFetchRelease[];
+}
+
+export interface RequestFollowImportLibrary {
+	provider: string;
+}
+
+export interface RequestFollowImportPlaylist {
+	provider: string;
+	id: string;
+}
+
+export interface RequestFollowImportConfirm {
+	follows: RequestFollow[];
+}
+
+export interface RequestSubscription {
+	provider: string;
+	id: string;
+	dropRemoved: boolean;
+}
+
+export interface RequestSubscriptionUpdate {
+	dropRemoved: boolean;
+}
+
+export interface RequestPlaylist {
+	name: string;
+}
+
+export interface RequestPlaylistSongs {
+	ids: number[];
+}
+
+export interface RequestPlaylistOrder {
+	ids: number[];
+}
+
+export interface RequestExternalConfirm {
+	provider: string;
+	ids: string[];
+}
+
+export interface RequestDownloadUrls {
+	text: string;
+}
+
+export interface RequestTagProfile {
+	profile: TagProfile;
+}
+
+export type BulkOperation = "set" | "clear" | "replace" | "renumber";
+
+export interface RequestBulkOperation {
+	op: BulkOperation;
+	tag?: string;
+	values?: string[];
+	find?: string;
+	replace?: string;
+	start?: number;
+	perDisc?: boolean;
+}
+
+export interface RequestBulkEdit {
+	ids?: number[];
+	album?: {
+		albumArtist: string;
+		album: string;
+	};
+	operations: RequestBulkOperation[];
 }
@@ -6,9 +6,23 @@ export interface ErrorResponse {
 	error: string;
 }
 
+export type QualityLevel = "HIRES" | "LOSSLESS" | "HIGH" | "LOW";
+
+export interface QualityPolicy {
+	preferences: QualityLevel[];
+	minimum: QualityLevel;
+	fallback: boolean;
+}
+
+export type QualityPolicyResponse = QualityPolicy;
+
 export interface User {
 	username: string;
 	hiRes: boolean;
+	qualityPreferences: QualityLevel[] | null;
+	qualityMinimum: QualityLevel;
+	qualityFallback: boolean;
+	autoFetchSchedule: string;
 }
 
 export type UserResponse = User;
@@ -36,6 +50,21 @@ export interface FollowItem {
 	artistId: string;
 	artistName: string;
 	artistPictureUrl: string;
+	lastFetchedAt: string | null;
+	filters: FollowFilters;
+}
+
+export type ReleaseType = "album" | "ep" | "single";
+
+export type ExplicitPreference = "any" | "explicit" | "clean";
+
+export interface FollowFilters {
+	releaseTypes: ReleaseType[] | null;
+	skipSinglesOnAlbum: boolean;
+	explicit: ExplicitPreference;
+	includePatterns: string[] | null;
+	excludePatterns: string[] | null;
+	minimumQuality: QualityLevel | "";
 }
 export type Follow = FollowItem;
 
@@ -43,13 +72,39 @@ export type FollowsResponse = FollowItem[];
 
 export interface DownloadData {
 	id: number;
+	jobId: number;
 	data: SongData;
 	provider: string;
 	status: "pending" | "running" | "done" | "failed" | "cancel";
+	error: string;
+	errorClass: "" | "network" | "instance" | "region" | "quality" | "tagging" | "unknown";
+	attempts: number;
+	retryAt?: string;
 }
 
 export type DownloadListResponse = DownloadData[];
 
+export interface DownloadJobData {
+	id: number;
+	request: {
+		provider: string;
+		type: "artist" | "album" | "song" | "playlist";
+		id: string;
+	};
+	title: string;
+	status: "pending" | "running" | "done" | "failed" | "cancel";
+	total: number;
+	pending: number;
+	running: number;
+	done: number;
+	failed: number;
+	cancel: number;
+	progress: number;
+	tasks?: DownloadData[];
+}
+
+export type DownloadJobListResponse = DownloadJobData[];
+
 export interface Quality {
 	name: string;
 	color: string;
@@ -71,6 +126,12 @@ export interface SongData {
 	popularity: number;
 	isrc: string;
 	explicit: boolean;
+	genres: string[];
+	copyright: string;
+	composers: string[];
+	lyricists: string[];
+	bpm: number;
+	key: string;
 	artists: SongDataArtist[];
 	album: SongDataAlbum;
 }
@@ -121,6 +182,10 @@ export interface AlbumData {
 	coverUrl: string;
 	audioQuality: Quality;
 	explicit: boolean;
+	genres: string[];
+	label: string;
+	copyright: string;
+	upc: string;
 	artists: AlbumDataArtist[];
 	songs: AlbumDataSong[];
 }
@@ -168,6 +233,13 @@ export interface SearchData {
 	albums: SearchDataAlbum[];
 	artists: SearchDataArtist[];
 	playlists: SearchDataPlaylist[];
+	total: SearchDataTotal;
+}
+export interface SearchDataTotal {
+	songs: number;
+	albums: number;
+	artists: number;
+	playlists: number;
 }
 export interface SearchDataSong {
 	downloaded: boolean;
@@ -219,6 +291,8 @@ export interface UrlItem {
 export type SearchResponse =
 	| {
 		result: SearchResult;
+		limit: number;
+		offset: number;
 	}
 	| {
 		url: UrlItem;
@@ -239,6 +313,45 @@ export interface ResponseSong {
 	albumPeak: number;
 	trackGain: number;
 	trackPeak: number;
+	genres: string[];
+	label: string;
+	copyright: string;
+	barcode: string;
+	composers: string[];
+	lyricists: string[];
+	bpm: number;
+	key: string;
+	trackTotal: number;
+	discTotal: number;
+	audioQuality: QualityLevel | "";
+}
+
+export interface ResponseLibraryQuality {
+	quality: QualityLevel | "";
+	count: number;
+}
+
+export type ResponseLibraryQualityList = ResponseLibraryQuality[];
+
+export interface UpgradeCandidate {
+	id: number;
+	userId: number;
+	songId: number;
+	path: string;
+	isrc: string;
+	codec: string;
+	sampleRate: number;
+	bitrate: number;
+	current: QualityLevel;
+	provider: string;
+	providerId: string;
+	available: QualityLevel;
+	scannedAt: string;
+}
+
+export interface ResponseUpgrades {
+	scanning: boolean;
+	items: UpgradeCandidate[];
 }
 
 export interface ResponseLibrary {
@@ -248,3 +361,369 @@ export interface ResponseLibrary {
 	offset: number;
 	items: ResponseSong[];
 }
+
+export interface SettingsResponse {
+	downloadGlobalLimit: number;
+	downloadUserLimit: number;
+	downloadRetryLimit: number;
+	downloadRetryDelay: number;
+	autoFetchSchedule: string;
+	cacheSongTTL: number;
+	cacheAlbumTTL: number;
+	cacheArtistTTL: number;
+	cachePlaylistTTL: number;
+	coverMaxSize: number;
+	coverFolderArt: boolean;
+	coverArtistArt: boolean;
+	trashRetentionDays: number;
+}
+
+export type AutoFetchTrigger = "schedule" | "catchup" | "manual";
+
+export interface AutoFetchRun {
+	id: number;
+	userId: number;
+	trigger: AutoFetchTrigger;
+	startedAt: string;
+	finishedAt: string;
+	follows: number;
+	found: number;
+	queued: number;
+	failed: number;
+	errors: string[] | null;
+}
+
+export type AutoFetchRunsResponse = AutoFetchRun[];
+
+export interface AutoFetchResponse {
+	schedule: string;
+	globalSchedule: string;
+	next: string | null;
+}
+
+export interface ReleaseItem {
+	followId: number;
+	artistId: string;
+	artistName: string;
+	provider: string;
+	id: string;
+	type: ReleaseType;
+	title: string;
+	releaseDate: string;
+	coverUrl: string;
+	explicit: boolean;
+	audioQuality: QualityLevel | "";
+	owned: boolean;
+}
+
+export interface ReleasesResponse {
+	since: string | null;
+	items: ReleaseItem[];
+	errors: string[];
+}
+
+export interface FeedEntry {
+	id: number;
+	userId: number;
+	provider: string;
+	albumId: string;
+	followId: number;
+	artistId: string;
+	artistName: string;
+	type: ReleaseType;
+	title: string;
+	releaseDate: string;
+	coverUrl: string;
+	explicit: boolean;
+	audioQuality: QualityLevel | "";
+	queued: boolean;
+	downloaded: boolean;
+	createdAt: string;
+}
+
+export interface FeedResponse {
+	total: number;
+	count: number;
+	limit: number;
+	offset: number;
+	items: FeedEntry[];
+}
+
+export interface FeedTokenResponse {
+	token: string;
+}
+
+export interface FollowImportSkipped {
+	provider: string;
+	artistId: string;
+	name: string;
+	reason: string;
+}
+
+export interface FollowImportAmbiguous {
+	name: string;
+	candidates: SearchDataArtist[];
+}
+
+export interface FollowImportResponse {
+	created: FollowItem[];
+	skipped: FollowImportSkipped[];
+	unmatched: string[];
+	ambiguous: FollowImportAmbiguous[];
+}
+
+export type MissingStatus = "complete" | "partial" | "missing";
+
+export interface MissingTrack {
+	id: string;
+	title: string;
+	trackNumber: number;
+	volumeNumber: number;
+	isrc: string;
+}
+
+export interface MissingRelease {
+	followId: number;
+	artistName: string;
+	provider: string;
+	id: string;
+	type: ReleaseType;
+	title: string;
+	releaseDate: string;
+	coverUrl: string;
+	status: MissingStatus;
+	total: number;
+	owned: number;
+	missing: MissingTrack[];
+}
+
+export interface MissingResponse {
+	complete: number;
+	partial: number;
+	missing: number;
+	items: MissingRelease[];
+	errors: string[];
+}
+
+export interface MissingQueueResponse {
+	albums: number;
+	songs: number;
+}
+
+export interface PlaylistSubscription {
+	id: number;
+	userId: number;
+	provider: string;
+	playlistId: string;
+	title: string;
+	coverUrl: string;
+	path: string;
+	dropRemoved: boolean;
+	lastSyncedAt: string | null;
+	createdAt: string;
+}
+
+export type SubscriptionsResponse = PlaylistSubscription[];
+
+export interface SubscriptionSyncResponse {
+	added: number;
+	queued: number;
+	removed: number;
+	dropped: number;
+}
+
+export interface ResponsePlaylist {
+	id: number;
+	name: string;
+	path: string;
+	count: number;
+	createdAt: string;
+	updatedAt: string;
+}
+
+export type PlaylistsResponse = ResponsePlaylist[];
+
+export interface ResponsePlaylistItem {
+	id: number;
+	position: number;
+	song: ResponseSong;
+}
+
+export interface ResponsePlaylistDetail extends ResponsePlaylist {
+	items: ResponsePlaylistItem[];
+}
+
+export interface ResponsePlaylistImport {
+	playlist: ResponsePlaylist;
+	matched: number;
+	unmatched: string[];
+}
+
+export type ExternalMatchStatus = "matched" | "ambiguous" | "unmatched";
+
+export interface ExternalCandidate {
+	id: string;
+	title: string;
+	artists: string[];
+	album: string;
+	duration: number;
+	isrc: string;
+	coverUrl: string;
+	score: number;
+}
+
+export interface ExternalRow {
+	index: number;
+	title: string;
+	artist: string;
+	album: string;
+	isrc: string;
+	duration: number;
+	status: ExternalMatchStatus;
+	match: ExternalCandidate | null;
+	candidates: ExternalCandidate[];
+}
+
+export interface ExternalImportResponse {
+	provider: string;
+	name: string;
+	matched: number;
+	ambiguous: number;
+	unmatched: number;
+	rows: ExternalRow[];
+}
+
+export interface ExternalConfirmResponse {
+	queued: number;
+}
+
+export interface DownloadUrlResult {
+	url: string;
+	item: UrlItem | null;
+	error: string;
+}
+
+export interface DownloadUrlsResponse {
+	queued: number;
+	failed: number;
+	results: DownloadUrlResult[];
+}
+
+export interface UnifiedSource {
+	provider: string;
+	id: string;
+	audioQuality: string;
+}
+
+export interface UnifiedLocal {
+	songId: number;
+	path: string;
+	audioQuality: QualityLevel | "";
+}
+
+export interface UnifiedSong {
+	isrc: string;
+	title: string;
+	artists: string[];
+	album: string;
+	duration: number;
+	coverUrl: string;
+	explicit: boolean;
+	score: number;
+	sources: UnifiedSource[];
+	local: UnifiedLocal[];
+}
+
+export interface UnifiedAlbum {
+	title: string;
+	artists: string[];
+	coverUrl: string;
+	explicit: boolean;
+	score: number;
+	sources: UnifiedSource[];
+	local: UnifiedLocal[];
+}
+
+export interface UnifiedArtist {
+	name: string;
+	pictureUrl: string;
+	score: number;
+	sources: UnifiedSource[];
+}
+
+export interface UnifiedSearchResponse {
+	query: string;
+	limit: number;
+	offset: number;
+	total: {
+		songs: number;
+		albums: number;
+		artists: number;
+	};
+	songs: UnifiedSong[];
+	albums: UnifiedAlbum[];
+	artists: UnifiedArtist[];
+}
+
+export interface CacheKindStats {
+	memoryHits: number;
+	databaseHits: number;
+	misses: number;
+}
+
+export interface CacheStatsResponse {
+	kinds: Record<"song" | "album" | "artist" | "playlist", CacheKindStats>;
+	memoryEntries: number;
+	databaseEntries: number;
+}
+
+export type TagProfile = "navidrome" | "jellyfin" | "plex" | "kodi" | "id3v23";
+
+export interface TagProfileResponse {
+	profile: TagProfile;
+	profiles: TagProfile[];
+}
+
+export interface BulkEditSong {
+	id: number;
+	oldPath: string;
+	path: string;
+}
+
+export interface BulkEditResponse {
+	songs: BulkEditSong[];
+}
+
+export type HistoryActor = "edit" | "upload" | "bulk" | "sync" | "revert";
+
+export interface TagChange {
+	tag: string;
+	old: string[] | null;
+	new: string[] | null;
+}
+
+export interface SongHistoryEntry {
+	songId: number;
+	rev: number;
+	actor: HistoryActor;
+	oldPath: string;
+	path: string;
+	changes: TagChange[];
+	createdAt: string;
+}
+
+export interface TrashItem {
+	id: number;
+	songId: number;
+	path: string;
+	isrc: string;
+	audioQuality: string;
+	size: number;
+	deletedAt: string;
+	purgeAt: string | null;
+}
+
+export interface TrashResponse {
+	items: TrashItem[];
+	retentionDays: number;
+}
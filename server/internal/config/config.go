@@ -28,7 +28,8 @@ func checkLibraryDirectory(dir string) error {
 	return nil
 }
 
-func init() {
+// Load reads the environment, the server cannot start without a writable library
+func Load() {
 	if err := godotenv.Load("../.env"); err != nil {
 		log.Println(".env not found")
 	}
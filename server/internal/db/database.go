@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
 	"golang.org/x/crypto/bcrypt"
@@ -33,12 +34,25 @@ func initAdmin() (*models.Admin, error) {
 func migrationDB(db *gorm.DB) error {
 	m := db.Migrator()
 
-	m = m
+	// the path of a song in the trash can be taken again, the unique index only covers the live songs
+	if m.HasIndex(&models.Song{}, "idx_song_user_path") {
+		if err := m.DropIndex(&models.Song{}, "idx_song_user_path"); err != nil {
+			return fmt.Errorf("database.migrationDB: %w", err)
+		}
+	}
+
+	// follows created before the fetch date was persisted resume from the last scheduled run
+	if err := db.Model(&models.Follow{}).
+		Where("last_fetched_at IS NULL").
+		Update("last_fetched_at", time.Now().Add(-24*time.Hour)).Error; err != nil {
+		return fmt.Errorf("database.migrationDB: %w", err)
+	}
 
 	return nil
 }
 
-func init() {
+// Connect opens the database and migrates its schema, the server cannot start without it
+func Connect() {
 	postgresHost := os.Getenv("POSTGRES_HOST")
 	postgresUser := os.Getenv("POSTGRES_USER")
 	postgresPassword := os.Getenv("POSTGRES_PASSWORD")
@@ -50,7 +64,7 @@ func init() {
 		log.Fatal("database.init:", err)
 	}
 
-	if err := db.AutoMigrate(&models.User{}, &models.UserSession{}, &models.Instance{}, &models.Follow{}, &models.Song{}, &models.Admin{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.UserSession{}, &models.Instance{}, &models.Follow{}, &models.Song{}, &models.Admin{}, &models.Settings{}, &models.UpgradeCandidate{}, &models.AutoFetchRun{}, &models.FeedEntry{}, &models.PlaylistSubscription{}, &models.Playlist{}, &models.PlaylistItem{}, &models.MetadataCache{}, &models.SongHistory{}, &models.TrashItem{}); err != nil {
 		log.Fatal("database.init:", err)
 	}
 
@@ -68,5 +82,10 @@ func init() {
 		log.Fatal("database.init:", err)
 	}
 
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Settings{ID: true}).Error
+	if err != nil {
+		log.Fatal("database.init:", err)
+	}
+
 	DB = db
 }
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/config"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/autofetch"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -106,3 +109,119 @@ func AdminLogout(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+func AdminSettings(c *gin.Context) {
+	settings, err := repository.GetSettings()
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func AdminUpdateSettings(c *gin.Context) {
+	var req models.RequestSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := repository.GetSettings()
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ApplyRequestSettings(settings, req); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := repository.UpdateSettings(settings); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	services.DownloadManager.ApplySettings(settings)
+	plugins.Cache.ApplySettings(settings)
+	if err := autofetch.Reload(); err != nil {
+		log.Println(err)
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func AdminListAutoFetchRuns(c *gin.Context) {
+	var limit int
+	if result, err := strconv.Atoi(c.Query("limit")); err != nil {
+		limit = 50
+	} else {
+		limit = result
+	}
+
+	var offset int
+	if result, err := strconv.Atoi(c.Query("offset")); err != nil {
+		offset = 0
+	} else {
+		offset = result
+	}
+
+	runs, err := repository.ListAutoFetchRuns(limit, offset)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func AdminRunAutoFetch(c *gin.Context) {
+	if err := autofetch.RunAll(); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func AdminCacheStats(c *gin.Context) {
+	stats, err := plugins.Cache.Stats()
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// AdminInvalidateCache drops the cached provider responses, optionally filtered by provider, type and id
+func AdminInvalidateCache(c *gin.Context) {
+	kind := models.Type(c.Query("type"))
+	switch kind {
+	case "", models.TypeSong, models.TypeAlbum, models.TypeArtist, models.TypePlaylist:
+	default:
+		err := fmt.Errorf("invalid type %q", kind)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	removed, err := plugins.Cache.Invalidate(c.Query("provider"), kind, c.Query("id"))
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "removed": removed})
+}
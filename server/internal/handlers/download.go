@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -30,13 +32,13 @@ func AddDownload(c *gin.Context) {
 
 	switch req.Type {
 	case "song":
-		services.DownloadManager.AddSong(userId, req.Provider, req.Id)
+		services.DownloadManager.AddSong(userId, req.Provider, req.Id, models.PriorityManual)
 	case "album":
-		services.DownloadManager.AddAlbum(userId, req.Provider, req.Id)
+		services.DownloadManager.AddAlbum(userId, req.Provider, req.Id, models.PriorityManual)
 	case "artist":
-		services.DownloadManager.AddArtist(userId, req.Provider, req.Id)
+		services.DownloadManager.AddArtist(userId, req.Provider, req.Id, models.PriorityManual)
 	case "playlist":
-		services.DownloadManager.AddPlaylist(userId, req.Provider, req.Id)
+		services.DownloadManager.AddPlaylist(userId, req.Provider, req.Id, models.PriorityManual)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
 		return
@@ -160,3 +162,218 @@ func DoneDownloads(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+func ListDownloadJobs(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expand, _ := strconv.ParseBool(c.Query("expand"))
+
+	jobs := services.DownloadManager.ListJobs(userId, expand)
+	c.JSON(http.StatusOK, jobs)
+}
+
+func DeleteDownloadJob(c *gin.Context) {
+	jobIdBadType, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	jobId := uint(jobIdBadType)
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = services.DownloadManager.RemoveJob(userId, jobId)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func RetryDownloadJob(c *gin.Context) {
+	jobIdBadType, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	jobId := uint(jobIdBadType)
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = services.DownloadManager.RetryJob(userId, jobId)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func CancelDownloadJob(c *gin.Context) {
+	jobIdBadType, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	jobId := uint(jobIdBadType)
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = services.DownloadManager.CancelJob(userId, jobId)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func ImportExternalPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestExternalImport
+	if err := c.ShouldBind(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBind: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.File == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "RequestExternalImport.file is empty"})
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		err := fmt.Errorf("req.File.Open: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := services.MatchExternalPlaylist(c.Request.Context(), userId, req.Provider, req.File.Filename, file)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ConfirmExternalPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestExternalConfirm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queued, err := services.QueueExternalSongs(userId, req.Provider, req.Ids)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queued": queued})
+}
+
+func AddDownloadUrls(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxDownloadUrlsSize)
+	var req models.RequestDownloadUrls
+	if err := c.ShouldBind(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBind: %w", err)
+		log.Println(err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	text := req.Text
+	if req.File != nil {
+		file, err := req.File.Open()
+		if err != nil {
+			err := fmt.Errorf("req.File.Open: %w", err)
+			log.Println(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			err := fmt.Errorf("io.ReadAll: %w", err)
+			log.Println(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		text += "\n" + string(content)
+	}
+
+	urls := services.ExtractUrls(text)
+	if len(urls) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no url given"})
+		return
+	}
+
+	result, err := services.QueueUrls(c.Request.Context(), userId, urls)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func ListFeed(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var limit int
+	if result, err := strconv.Atoi(c.Query("limit")); err != nil {
+		limit = 20
+	} else {
+		limit = result
+	}
+
+	var offset int
+	if result, err := strconv.Atoi(c.Query("offset")); err != nil {
+		offset = 0
+	} else {
+		offset = result
+	}
+
+	total, err := repository.CountFeedEntriesByUserID(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	entries, err := repository.ListFeedEntriesByUserID(userId, limit, offset)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseFeed{Total: int(total), Count: len(entries), Limit: limit, Offset: offset, Items: entries})
+}
+
+func FeedAtom(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	entries, err := repository.ListFeedEntriesByUserID(userId, 50, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	selfUrl := scheme + "://" + c.Request.Host + c.Request.URL.RequestURI()
+
+	data, err := services.BuildAtomFeed(selfUrl, entries)
+	if err != nil {
+		log.Println(err)
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", data)
+}
+
+func MeFeedToken(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := services.GetFeedToken(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseFeedToken{Token: token})
+}
+
+func MeRenewFeedToken(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := services.RenewFeedToken(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseFeedToken{Token: token})
+}
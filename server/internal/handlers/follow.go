@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/autofetch"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func AddFollow(c *gin.Context) {
@@ -65,6 +69,8 @@ func AddFollow(c *gin.Context) {
 		ArtistId:         follow.ArtistId,
 		ArtistName:       follow.ArtistName,
 		ArtistPictureUrl: follow.ArtistPictureUrl,
+		LastFetchedAt:    follow.LastFetchedAt,
+		Filters:          follow.Filters,
 	}
 
 	c.JSON(http.StatusOK, data)
@@ -93,7 +99,7 @@ func ListFollows(c *gin.Context) {
 		go func(i int, follow models.Follow) {
 			defer wg.Done()
 
-			follows[i] = models.FollowItem{Id: follow.ID, Provider: follow.Provider, ArtistId: follow.ArtistId, ArtistName: follow.ArtistName, ArtistPictureUrl: follow.ArtistPictureUrl}
+			follows[i] = models.FollowItem{Id: follow.ID, Provider: follow.Provider, ArtistId: follow.ArtistId, ArtistName: follow.ArtistName, ArtistPictureUrl: follow.ArtistPictureUrl, LastFetchedAt: follow.LastFetchedAt, Filters: follow.Filters}
 		}(index, value)
 	}
 
@@ -127,3 +133,315 @@ func DeleteFollow(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+func ListAutoFetchRuns(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var limit int
+	if result, err := strconv.Atoi(c.Query("limit")); err != nil {
+		limit = 20
+	} else {
+		limit = result
+	}
+
+	var offset int
+	if result, err := strconv.Atoi(c.Query("offset")); err != nil {
+		offset = 0
+	} else {
+		offset = result
+	}
+
+	runs, err := repository.ListAutoFetchRunsByUserID(userId, limit, offset)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func GetFollowFilters(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idUint64, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id := uint(idUint64)
+
+	follow, err := repository.GetFollowByUserID(userId, id)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "follow not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, follow.Filters)
+}
+
+func UpdateFollowFilters(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	idUint64, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id := uint(idUint64)
+
+	var req models.RequestFollowFilters
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Explicit == "" {
+		req.Explicit = models.ExplicitAny
+	}
+
+	if err := services.ValidateFollowFilters(req); err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := repository.UpdateFollowFiltersByUserID(userId, id, req); err != nil {
+		log.Println(err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "follow not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+func PreviewReleases(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var since *time.Time
+	if value := c.Query("since"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			err := fmt.Errorf("time.Parse: %w", err)
+			log.Println(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		since = &date
+	}
+
+	releases, err := autofetch.Preview(c.Request.Context(), userId, since)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, releases)
+}
+
+func FetchReleases(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestFetchReleases
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, release := range req.Releases {
+		if _, ok := plugins.GetPluginByProvider(release.Provider); !ok {
+			err := fmt.Errorf("invalid provider name %q", release.Provider)
+			log.Println(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	for _, release := range req.Releases {
+		services.DownloadManager.AddAlbum(userId, release.Provider, release.Id, models.PriorityManual)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "queued": len(req.Releases)})
+}
+
+func ImportFollowsFromLibrary(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestFollowImportLibrary
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := services.ImportFollowsFromLibrary(c.Request.Context(), userId, req.Provider)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ImportFollowsFromPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestFollowImportPlaylist
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := services.ImportFollowsFromPlaylist(c.Request.Context(), userId, req.Provider, req.Id)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ConfirmFollowsImport(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RequestFollowImportConfirm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err := fmt.Errorf("c.ShouldBindJSON: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := services.ConfirmFollows(c.Request.Context(), userId, req.Follows)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// missingFollows returns the follow of the :id param, or every follow of the user on the routes without it
+func missingFollows(c *gin.Context, userId uint) ([]models.Follow, bool) {
+	if c.Param("id") == "" {
+		follows, err := repository.ListFollowsByUserID(userId)
+		if err != nil {
+			log.Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		return follows, true
+	}
+
+	idUint64, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		err := fmt.Errorf("strconv.ParseUint: %w", err)
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, false
+	}
+
+	follow, err := repository.GetFollowByUserID(userId, uint(idUint64))
+	if err != nil {
+		log.Println(err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "follow not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return []models.Follow{*follow}, true
+}
+
+func ListMissing(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	follows, ok := missingFollows(c, userId)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, services.MissingDiscography(c.Request.Context(), follows))
+}
+
+func QueueMissing(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	follows, ok := missingFollows(c, userId)
+	if !ok {
+		return
+	}
+
+	report := services.MissingDiscography(c.Request.Context(), follows)
+	c.JSON(http.StatusOK, services.QueueMissing(userId, report))
+}
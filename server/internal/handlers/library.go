@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -80,7 +81,7 @@ func UploadSong(c *gin.Context) {
 		}
 	}
 
-	newTags := upload.ToTags()
+	newTags := metadata.ApplyProfile(metadata.GetUserProfile(userId), upload.ToTags())
 	if err := metadata.WriteTags(tmpFile.Name(), newTags, false); err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError, err)
 		return
@@ -97,7 +98,12 @@ func UploadSong(c *gin.Context) {
 		return
 	}
 
-	if err := repository.AddSong(models.Song{UserId: userId, Path: path}); err != nil {
+	quality, err := metadata.ReadQuality(tmpFile.Name())
+	if err != nil {
+		log.Println(err)
+	}
+
+	if err := repository.AddSong(&models.Song{UserId: userId, Path: path, AudioQuality: quality}); err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError, err)
 		return
 	}
@@ -139,6 +145,12 @@ func UploadSong(c *gin.Context) {
 		log.Println(fmt.Errorf("newFile.Sync: %w", err))
 	}
 
+	if song, err := repository.GetSongByUserIDByPath(userId, path); err != nil {
+		log.Println(err)
+	} else if err := services.RecordSongHistory(userId, song.ID, models.HistoryUpload, "", path, nil, tags); err != nil {
+		log.Println(err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
@@ -169,6 +181,12 @@ func EditSong(c *gin.Context) {
 		return
 	}
 
+	oldTags, err := metadata.ReadTags(filepath.Join(userPath, song.Path))
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
 	originalFile, err := os.Open(filepath.Join(userPath, song.Path))
 	if err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError,
@@ -195,7 +213,7 @@ func EditSong(c *gin.Context) {
 		return
 	}
 
-	newTags := edit.ToTags()
+	newTags := metadata.ApplyProfile(metadata.GetUserProfile(userId), edit.ToTags())
 	if err := metadata.WriteTags(copyFile.Name(), newTags, false); err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError, err)
 		return
@@ -273,6 +291,13 @@ func EditSong(c *gin.Context) {
 	}
 
 	copyFile = nil
+	if path != song.Path {
+		services.RemoveThumbnails(userId, song.Path)
+		services.RefreshSongPlaylists(userId, song.ID)
+	}
+	if err := services.RecordSongHistory(userId, song.ID, models.HistoryEdit, song.Path, path, oldTags, tags); err != nil {
+		log.Println(err)
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
@@ -291,13 +316,57 @@ func GetSongCover(c *gin.Context) {
 	}
 	id := uint(result)
 
-	img, err := services.GetLibrarySongCover(userId, id)
+	var size uint
+	if value := c.Query("size"); value != "" {
+		result, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			utils.GinPrettyError(c, http.StatusBadRequest,
+				fmt.Errorf("strconv.ParseUint: %w", err))
+			return
+		}
+		size = uint(result)
+	}
+
+	cover, err := services.GetLibraryCoverInfo(userId, id, size)
 	if err != nil {
-		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		switch {
+		case errors.Is(err, services.ErrInvalidSize):
+			utils.GinPrettyError(c, http.StatusBadRequest, err)
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrNoCover):
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		default:
+			utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	// covers are revalidated on every use, an unchanged cover only costs a 304
+	c.Header("ETag", cover.ETag)
+	c.Header("Last-Modified", cover.ModTime.Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
+	if notModified(c, cover) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	c.Data(http.StatusOK, "image/jpg", img)
+	c.Header("Content-Type", cover.Mime)
+	c.File(cover.Path)
+}
+
+func notModified(c *gin.Context, cover models.LibraryCover) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for tag := range strings.SplitSeq(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == cover.ETag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+		return !cover.ModTime.Truncate(time.Second).After(since)
+	}
+	return false
 }
 
 func ListSong(c *gin.Context) {
@@ -325,13 +394,19 @@ func ListSong(c *gin.Context) {
 	q = strings.ReplaceAll(q, "_", "\\_")
 	q = strings.ReplaceAll(q, "/", "\\_")
 
-	total, err := repository.CountSongByUserID(userId, q)
+	quality := models.QualityLevel(strings.ToUpper(c.Query("quality")))
+	if quality != "" && !quality.Valid() {
+		utils.GinPrettyError(c, http.StatusBadRequest, errors.New("invalid quality"))
+		return
+	}
+
+	total, err := repository.CountSongByUserID(userId, q, quality)
 	if err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	songs, err := repository.ListSongByUserID(userId, q, limit, offset)
+	songs, err := repository.ListSongByUserID(userId, q, quality, limit, offset)
 	if err != nil {
 		utils.GinPrettyError(c, http.StatusInternalServerError, err)
 		return
@@ -350,6 +425,76 @@ func ListSong(c *gin.Context) {
 	c.JSON(http.StatusOK, models.ResponseLibrary{Total: int(total), Count: len(list), Limit: limit, Offset: offset, Items: list})
 }
 
+func ListSongQuality(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	counts, err := repository.CountSongByUserIDByQuality(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+func ListUpgrades(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	candidates, err := repository.ListUpgradeCandidatesByUserID(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ResponseUpgrades{Scanning: services.IsLibraryUpgradeScanning(userId), Items: candidates})
+}
+
+func ScanUpgrades(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.StartLibraryUpgradeScan(userId); err != nil {
+		utils.GinPrettyError(c, http.StatusConflict, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func QueueUpgrades(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.RequestUpgrade
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	queued, err := services.QueueLibraryUpgrades(userId, req.Ids)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "queued": queued})
+}
+
 func DeleteSong(c *gin.Context) {
 	userId, err := utils.GetFromContext[uint](c, "userId")
 	if err != nil {
@@ -387,3 +532,189 @@ func SyncLibrary(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+func BulkEditSongs(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.RequestBulkEdit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	result, err := services.BulkEditLibrary(userId, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrBulkConflict):
+			utils.GinPrettyError(c, http.StatusConflict, err)
+		default:
+			utils.GinPrettyError(c, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ListSongHistory(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	id := uint(result)
+
+	history, err := services.ListSongHistory(userId, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		} else {
+			utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func RevertSongHistory(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	id := uint(result)
+
+	result, err = strconv.ParseUint(c.Param("rev"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	rev := uint(result)
+
+	song, err := services.RevertSongHistory(userId, id, rev)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrBulkConflict):
+			utils.GinPrettyError(c, http.StatusConflict, err)
+		default:
+			utils.GinPrettyError(c, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, song)
+}
+
+func ListTrash(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	trash, err := services.ListTrash(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+func RestoreTrashItem(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	id := uint(result)
+
+	song, err := services.RestoreTrashItem(userId, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrTrashConflict):
+			utils.GinPrettyError(c, http.StatusConflict, err)
+		default:
+			utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, song)
+}
+
+func PurgeTrashItem(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	id := uint(result)
+
+	if err := services.PurgeTrashItem(userId, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.GinPrettyError(c, http.StatusNotFound, err)
+		} else {
+			utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func EmptyTrash(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.EmptyTrash(userId); err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
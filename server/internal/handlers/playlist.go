@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func playlistErrorCode(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func playlistId(c *gin.Context) (uint, bool) {
+	result, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return 0, false
+	}
+	return uint(result), true
+}
+
+func CreateLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.RequestPlaylist
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	result, err := services.CreatePlaylist(userId, req.Name)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func ListLibraryPlaylists(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := repository.ListPlaylistsByUserID(userId)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func GetLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	result, err := services.GetPlaylist(userId, id)
+	if err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func UpdateLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	var req models.RequestPlaylist
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	if err := services.RenamePlaylist(userId, id, req.Name); err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func DeleteLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeletePlaylist(userId, id); err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func AddLibraryPlaylistSongs(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	var req models.RequestPlaylistSongs
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	if err := services.AddPlaylistSongs(userId, id, req.Ids); err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func DeleteLibraryPlaylistItem(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	result, err := strconv.ParseUint(c.Param("itemId"), 10, 0)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("strconv.ParseUint: %w", err))
+		return
+	}
+	itemId := uint(result)
+
+	if err := services.RemovePlaylistItem(userId, id, itemId); err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func ReorderLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	var req models.RequestPlaylistOrder
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBindJSON: %w", err))
+		return
+	}
+
+	if err := services.ReorderPlaylist(userId, id, req.Ids); err != nil {
+		code := playlistErrorCode(err)
+		if code == http.StatusInternalServerError {
+			code = http.StatusBadRequest
+		}
+		utils.GinPrettyError(c, code, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func ExportLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	id, ok := playlistId(c)
+	if !ok {
+		return
+	}
+
+	format, ok := playlist.FormatOf("." + c.DefaultQuery("format", string(playlist.FormatM3U8)))
+	if !ok {
+		utils.GinPrettyError(c, http.StatusBadRequest, errors.New("invalid playlist format"))
+		return
+	}
+
+	content, filename, err := services.ExportPlaylist(userId, id, format)
+	if err != nil {
+		utils.GinPrettyError(c, playlistErrorCode(err), err)
+		return
+	}
+
+	contentType := "audio/x-mpegurl"
+	switch format {
+	case playlist.FormatXSPF:
+		contentType = "application/xspf+xml"
+	case playlist.FormatCSV:
+		contentType = "text/csv"
+	case playlist.FormatJSON:
+		contentType = "application/json"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, content)
+}
+
+func ImportLibraryPlaylist(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var req models.RequestPlaylistImport
+	if err := c.ShouldBind(&req); err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("c.ShouldBind: %w", err))
+		return
+	}
+	if req.File == nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			errors.New("RequestPlaylistImport.file is empty"))
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest,
+			fmt.Errorf("req.File.Open: %w", err))
+		return
+	}
+	defer file.Close()
+
+	var name string
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	result, err := services.ImportPlaylist(userId, req.File.Filename, file, name)
+	if err != nil {
+		utils.GinPrettyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 
-	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -158,76 +161,70 @@ func Search(c *gin.Context) {
 	}
 
 	search := c.Query("q")
-	finding := make(map[string]models.SearchData)
-
-	for provider, pluginsList := range plugins.GetAllPluginsByProvider() {
-		var tmp models.SearchData
-		var err error
+	for _, pluginsList := range plugins.GetAllPluginsByProvider() {
 		for _, plugin := range pluginsList {
 			if urlItem, err := plugin.Url(c.Request.Context(), userId, search); err == nil {
 				c.JSON(http.StatusOK, gin.H{"url": urlItem})
 				return
 			}
-			tmp, err = plugin.Search(c.Request.Context(), userId, search, search, search)
-			if err == nil {
-				break
-			}
-		}
-		if err == nil {
-			var wg sync.WaitGroup
-			for i, song := range tmp.Songs {
-				_, err = repository.GetSongByUserIDByISRC(userId, song.Isrc)
-				if err == nil {
-					tmp.Songs[i].Downloaded = true
-				}
-			}
-			for i, album := range tmp.Albums {
-				wg.Add(1)
-				go func(i int) {
-					defer wg.Done()
-					albumData, err := plugins.GetAlbum(c.Request.Context(), userId, provider, album.Id)
-					if err != nil {
-						return
-					}
-					downloaded := true
-					for _, song := range albumData.Songs {
-						_, err = repository.GetSongByUserIDByISRC(userId, song.Isrc)
-						if err != nil {
-							downloaded = false
-							break
-						}
-					}
-					tmp.Albums[i].Downloaded = downloaded
-				}(i)
-			}
-			for i, artist := range tmp.Artists {
-				follow, err := repository.GetFollowByProviderByArtistID(provider, artist.Id)
-				if err == nil {
-					tmp.Artists[i].Followed = follow.ID
-				}
-			}
-			for i, playlist := range tmp.Playlists {
-				wg.Add(1)
-				go func(i int) {
-					defer wg.Done()
-					playlist, err := plugins.GetPlaylist(c.Request.Context(), userId, provider, playlist.ID)
-					if err != nil {
-						return
-					}
-					downloaded := true
-					for _, song := range playlist.Songs {
-						_, err = repository.GetSongByUserIDByISRC(userId, song.Isrc)
-						if err != nil {
-							downloaded = false
-							break
-						}
-					}
-					tmp.Playlists[i].Downloaded = downloaded
-				}(i)
-			}
-			wg.Wait()
-			finding[provider] = tmp
 		}
 	}
-	c.JSON(http.StatusOK, gin.H{"result": finding})
+
+	var limit uint = 25
+	if result, err := strconv.ParseUint(c.Query("limit"), 10, 0); err == nil && result > 0 {
+		limit = min(uint(result), 100)
+	}
+	var offset uint
+	if result, err := strconv.ParseUint(c.Query("offset"), 10, 0); err == nil {
+		offset = uint(result)
+	}
+
+	query, err := services.NewSearchQuery(search, c.Query("type"), limit, offset)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	finding, err := services.Search(c.Request.Context(), userId, query)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"result": finding, "limit": limit, "offset": offset})
+}
+
+func UnifiedSearch(c *gin.Context) {
+	userId, err := utils.GetFromContext[uint](c, "userId")
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search := strings.TrimSpace(c.Query("q"))
+	if search == "" {
+		err := errors.New("empty search")
+		log.Println(err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var limit uint = 25
+	if result, err := strconv.ParseUint(c.Query("limit"), 10, 0); err == nil && result > 0 {
+		limit = min(uint(result), 100)
+	}
+	var offset uint
+	if result, err := strconv.ParseUint(c.Query("offset"), 10, 0); err == nil {
+		offset = uint(result)
+	}
+
+	result, err := services.UnifiedSearch(c.Request.Context(), userId, search, limit, offset)
+	if err != nil {
+		log.Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/autofetch"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -44,6 +45,9 @@ func CreateUser(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := autofetch.Reload(); err != nil {
+		log.Println(err)
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
@@ -112,6 +116,14 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if req.Username != "" && req.Username != oldUser.Username {
+		if err := services.ValidateUsername(req.Username); err != nil {
+			log.Println(err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	err = repository.UpdateUser(uint(id), &req)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -163,6 +175,10 @@ func DeleteUser(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	services.RemoveUserData(uint(id))
+	if err := autofetch.Reload(); err != nil {
+		log.Println(err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
@@ -33,6 +33,31 @@ func Logged() gin.HandlerFunc {
 	}
 }
 
+// FeedToken authenticates feed readers with the token in the query, they can't hold a session cookie
+func FeedToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			err := errors.New("feed token missing")
+			log.Println(err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		user, err := repository.GetUserByFeedToken(token)
+		if err != nil {
+			log.Println(err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid feed token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("userId", user.ID)
+		c.Next()
+	}
+}
+
 func LoggedOut() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := c.Cookie("user_session")
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+type AutoFetchTrigger string
+
+const (
+	AutoFetchTriggerSchedule AutoFetchTrigger = "schedule"
+	AutoFetchTriggerCatchUp  AutoFetchTrigger = "catchup"
+	AutoFetchTriggerManual   AutoFetchTrigger = "manual"
+)
+
+type AutoFetchRun struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	UserId     uint             `gorm:"not null;index" json:"userId"`
+	Trigger    AutoFetchTrigger `gorm:"not null" json:"trigger"`
+	StartedAt  time.Time        `gorm:"index" json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	Follows    uint             `json:"follows"`
+	Found      uint             `json:"found"`
+	Queued     uint             `json:"queued"`
+	Failed     uint             `json:"failed"`
+	Errors     []string         `gorm:"type:text;serializer:json" json:"errors"`
+}
+
+type RequestAutoFetch struct {
+	Schedule string `json:"schedule"`
+}
+
+type ResponseAutoFetch struct {
+	Schedule       string     `json:"schedule"`
+	GlobalSchedule string     `json:"globalSchedule"`
+	Next           *time.Time `json:"next"`
+}
+
+type ReleaseItem struct {
+	FollowId     uint         `json:"followId"`
+	ArtistId     string       `json:"artistId"`
+	ArtistName   string       `json:"artistName"`
+	Provider     string       `json:"provider"`
+	Id           string       `json:"id"`
+	Type         ReleaseType  `json:"type"`
+	Title        string       `json:"title"`
+	ReleaseDate  string       `json:"releaseDate"`
+	CoverUrl     string       `json:"coverUrl"`
+	Explicit     bool         `json:"explicit"`
+	AudioQuality QualityLevel `json:"audioQuality"`
+	Owned        bool         `json:"owned"`
+}
+
+type ResponseReleases struct {
+	Since  *time.Time    `json:"since"`
+	Items  []ReleaseItem `json:"items"`
+	Errors []string      `json:"errors"`
+}
+
+type RequestFetchReleases struct {
+	Releases []RequestFetchRelease `json:"releases"`
+}
+
+type RequestFetchRelease struct {
+	Provider string `json:"provider"`
+	Id       string `json:"id"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type MetadataCache struct {
+	Provider  string    `gorm:"primaryKey"`
+	Kind      Type      `gorm:"primaryKey"`
+	EntityId  string    `gorm:"primaryKey"`
+	Data      []byte    `gorm:"type:bytea;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UpdatedAt time.Time
+}
+
+type CacheKindStats struct {
+	MemoryHits   uint64 `json:"memoryHits"`
+	DatabaseHits uint64 `json:"databaseHits"`
+	Misses       uint64 `json:"misses"`
+}
+
+type ResponseCacheStats struct {
+	Kinds           map[Type]CacheKindStats `json:"kinds"`
+	MemoryEntries   int                     `json:"memoryEntries"`
+	DatabaseEntries int64                   `json:"databaseEntries"`
+}
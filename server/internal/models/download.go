@@ -1,5 +1,11 @@
 package models
 
+import (
+	"errors"
+	"mime/multipart"
+	"time"
+)
+
 type Status string
 
 const (
@@ -10,6 +16,30 @@ const (
 	StatusCancel  Status = "cancel"
 )
 
+type ErrorClass string
+
+const (
+	ErrorNetwork  ErrorClass = "network"
+	ErrorInstance ErrorClass = "instance"
+	ErrorRegion   ErrorClass = "region"
+	ErrorQuality  ErrorClass = "quality"
+	ErrorTagging  ErrorClass = "tagging"
+	ErrorUnknown  ErrorClass = "unknown"
+)
+
+var (
+	ErrNotAvailable    = errors.New("not available in region")
+	ErrQualityMismatch = errors.New("audio quality received not conform")
+	ErrTagging         = errors.New("tagging failed")
+)
+
+type Priority int
+
+const (
+	PriorityAutoFetch Priority = iota
+	PriorityManual
+)
+
 type RequestDownload struct {
 	Provider string `json:"provider"`
 	Type     string `json:"type"`
@@ -17,8 +47,45 @@ type RequestDownload struct {
 }
 
 type DownloadData struct {
-	Id       uint     `json:"id"`
-	Provider string   `json:"provider"`
-	Data     SongData `json:"data"`
-	Status   Status   `json:"status"`
+	Id         uint       `json:"id"`
+	JobId      uint       `json:"jobId"`
+	Provider   string     `json:"provider"`
+	Data       SongData   `json:"data"`
+	Status     Status     `json:"status"`
+	Error      string     `json:"error"`
+	ErrorClass ErrorClass `json:"errorClass"`
+	Attempts   uint       `json:"attempts"`
+	RetryAt    *time.Time `json:"retryAt,omitempty"`
+}
+
+type DownloadJobData struct {
+	Id       uint            `json:"id"`
+	Request  RequestDownload `json:"request"`
+	Title    string          `json:"title"`
+	Status   Status          `json:"status"`
+	Total    int             `json:"total"`
+	Pending  int             `json:"pending"`
+	Running  int             `json:"running"`
+	Done     int             `json:"done"`
+	Failed   int             `json:"failed"`
+	Cancel   int             `json:"cancel"`
+	Progress float64         `json:"progress"`
+	Tasks    []DownloadData  `json:"tasks,omitempty"`
+}
+
+type RequestDownloadUrls struct {
+	Text string                `form:"text" json:"text"`
+	File *multipart.FileHeader `form:"file" json:"-"`
+}
+
+type DownloadUrlResult struct {
+	Url   string   `json:"url"`
+	Item  *UrlItem `json:"item"`
+	Error string   `json:"error"`
+}
+
+type ResponseDownloadUrls struct {
+	Queued  int                 `json:"queued"`
+	Failed  int                 `json:"failed"`
+	Results []DownloadUrlResult `json:"results"`
 }
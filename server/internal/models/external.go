@@ -0,0 +1,53 @@
+package models
+
+import "mime/multipart"
+
+type ExternalMatchStatus string
+
+const (
+	ExternalMatched   ExternalMatchStatus = "matched"
+	ExternalAmbiguous ExternalMatchStatus = "ambiguous"
+	ExternalUnmatched ExternalMatchStatus = "unmatched"
+)
+
+type RequestExternalImport struct {
+	File     *multipart.FileHeader `form:"file"`
+	Provider string                `form:"provider"`
+}
+
+type ExternalCandidate struct {
+	Id       string   `json:"id"`
+	Title    string   `json:"title"`
+	Artists  []string `json:"artists"`
+	Album    string   `json:"album"`
+	Duration uint     `json:"duration"`
+	Isrc     string   `json:"isrc"`
+	CoverUrl string   `json:"coverUrl"`
+	Score    float64  `json:"score"`
+}
+
+type ExternalRow struct {
+	Index      int                 `json:"index"`
+	Title      string              `json:"title"`
+	Artist     string              `json:"artist"`
+	Album      string              `json:"album"`
+	Isrc       string              `json:"isrc"`
+	Duration   int                 `json:"duration"`
+	Status     ExternalMatchStatus `json:"status"`
+	Match      *ExternalCandidate  `json:"match"`
+	Candidates []ExternalCandidate `json:"candidates"`
+}
+
+type ResponseExternalImport struct {
+	Provider  string        `json:"provider"`
+	Name      string        `json:"name"`
+	Matched   int           `json:"matched"`
+	Ambiguous int           `json:"ambiguous"`
+	Unmatched int           `json:"unmatched"`
+	Rows      []ExternalRow `json:"rows"`
+}
+
+type RequestExternalConfirm struct {
+	Provider string   `json:"provider"`
+	Ids      []string `json:"ids"`
+}
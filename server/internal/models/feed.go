@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type FeedEntry struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	UserId      uint         `gorm:"not null;uniqueIndex:idx_feed_release" json:"userId"`
+	Provider    string       `gorm:"not null;uniqueIndex:idx_feed_release" json:"provider"`
+	AlbumId     string       `gorm:"not null;uniqueIndex:idx_feed_release" json:"albumId"`
+	FollowId    uint         `json:"followId"`
+	ArtistId    string       `json:"artistId"`
+	ArtistName  string       `json:"artistName"`
+	Type        ReleaseType  `json:"type"`
+	Title       string       `json:"title"`
+	ReleaseDate string       `gorm:"index" json:"releaseDate"`
+	CoverUrl    string       `json:"coverUrl"`
+	Explicit    bool         `json:"explicit"`
+	Quality     QualityLevel `json:"audioQuality"`
+	Queued      bool         `json:"queued"`
+	Downloaded  bool         `json:"downloaded"`
+	CreatedAt   time.Time    `gorm:"index" json:"createdAt"`
+}
+
+type ResponseFeed struct {
+	Total  int         `json:"total"`
+	Count  int         `json:"count"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+	Items  []FeedEntry `json:"items"`
+}
+
+type ResponseFeedToken struct {
+	Token string `json:"token"`
+}
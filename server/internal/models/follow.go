@@ -1,23 +1,89 @@
 package models
 
+import "time"
+
 type RequestFollow struct {
 	Provider string `json:"provider"`
 	Id       string `json:"id"`
 }
 
+type ReleaseType string
+
+const (
+	ReleaseAlbum  ReleaseType = "album"
+	ReleaseEp     ReleaseType = "ep"
+	ReleaseSingle ReleaseType = "single"
+)
+
+type ExplicitPreference string
+
+const (
+	ExplicitAny      ExplicitPreference = "any"
+	ExplicitExplicit ExplicitPreference = "explicit"
+	ExplicitClean    ExplicitPreference = "clean"
+)
+
+// FollowFilters select which releases of a followed artist are fetched, empty lists keep everything
+type FollowFilters struct {
+	ReleaseTypes       []ReleaseType      `gorm:"type:text;serializer:json" json:"releaseTypes"`
+	SkipSinglesOnAlbum bool               `gorm:"not null;default:false" json:"skipSinglesOnAlbum"`
+	Explicit           ExplicitPreference `gorm:"not null;default:'any'" json:"explicit"`
+	IncludePatterns    []string           `gorm:"type:text;serializer:json" json:"includePatterns"`
+	ExcludePatterns    []string           `gorm:"type:text;serializer:json" json:"excludePatterns"`
+	MinimumQuality     QualityLevel       `gorm:"not null;default:''" json:"minimumQuality"`
+}
+
+type RequestFollowFilters = FollowFilters
+
 type Follow struct {
-	ID               uint   `gorm:"primaryKey" json:"id"`
-	UserId           uint   `gorm:"not null;uniqueIndex:idx_follow" json:"userId"`
-	Provider         string `gorm:"not null;uniqueIndex:idx_follow" json:"provider"`
-	ArtistId         string `gorm:"not null;uniqueIndex:idx_follow" json:"artistId"`
-	ArtistName       string `gorm:"not null" json:"artistName"`
-	ArtistPictureUrl string `gorm:"not null" json:"artistPictureUrl"`
+	ID               uint          `gorm:"primaryKey" json:"id"`
+	UserId           uint          `gorm:"not null;uniqueIndex:idx_follow" json:"userId"`
+	Provider         string        `gorm:"not null;uniqueIndex:idx_follow" json:"provider"`
+	ArtistId         string        `gorm:"not null;uniqueIndex:idx_follow" json:"artistId"`
+	ArtistName       string        `gorm:"not null" json:"artistName"`
+	ArtistPictureUrl string        `gorm:"not null" json:"artistPictureUrl"`
+	LastFetchedAt    *time.Time    `json:"lastFetchedAt"`
+	Filters          FollowFilters `gorm:"embedded" json:"filters"`
 }
 
 type FollowItem struct {
-	Id               uint   `json:"id"`
-	Provider         string `json:"provider"`
-	ArtistId         string `json:"artistId"`
-	ArtistName       string `json:"artistName"`
-	ArtistPictureUrl string `json:"artistPictureUrl"`
+	Id               uint          `json:"id"`
+	Provider         string        `json:"provider"`
+	ArtistId         string        `json:"artistId"`
+	ArtistName       string        `json:"artistName"`
+	ArtistPictureUrl string        `json:"artistPictureUrl"`
+	LastFetchedAt    *time.Time    `json:"lastFetchedAt"`
+	Filters          FollowFilters `json:"filters"`
+}
+
+type RequestFollowImportLibrary struct {
+	Provider string `json:"provider"`
+}
+
+type RequestFollowImportPlaylist struct {
+	Provider string `json:"provider"`
+	Id       string `json:"id"`
+}
+
+type RequestFollowImportConfirm struct {
+	Follows []RequestFollow `json:"follows"`
+}
+
+type FollowImportSkipped struct {
+	Provider string `json:"provider"`
+	ArtistId string `json:"artistId"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+}
+
+type FollowImportAmbiguous struct {
+	Name       string             `json:"name"`
+	Candidates []SearchDataArtist `json:"candidates"`
+}
+
+type ResponseFollowImport struct {
+	Created   []FollowItem            `json:"created"`
+	Skipped   []FollowImportSkipped   `json:"skipped"`
+	Unmatched []string                `json:"unmatched"`
+	Ambiguous []FollowImportAmbiguous `json:"ambiguous"`
 }
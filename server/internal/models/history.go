@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type HistoryActor string
+
+const (
+	HistoryEdit   HistoryActor = "edit"
+	HistoryUpload HistoryActor = "upload"
+	HistoryBulk   HistoryActor = "bulk"
+	HistorySync   HistoryActor = "sync"
+	HistoryRevert HistoryActor = "revert"
+)
+
+type TagChange struct {
+	Tag string   `json:"tag"`
+	Old []string `json:"old"`
+	New []string `json:"new"`
+}
+
+// SongHistory records one change of the tags or the path of a song, Tags keeps every tag after the
+// change so a file edited outside of the app can be diffed by the next sync
+type SongHistory struct {
+	ID        uint                `gorm:"primaryKey" json:"-"`
+	UserId    uint                `gorm:"not null;index" json:"-"`
+	SongId    uint                `gorm:"not null;uniqueIndex:idx_history_song_rev" json:"songId"`
+	Song      Song                `gorm:"foreignKey:SongId;constraint:OnDelete:CASCADE" json:"-"`
+	Rev       uint                `gorm:"not null;uniqueIndex:idx_history_song_rev" json:"rev"`
+	Actor     HistoryActor        `gorm:"not null" json:"actor"`
+	OldPath   string              `json:"oldPath"`
+	Path      string              `json:"path"`
+	Changes   []TagChange         `gorm:"type:text;serializer:json" json:"changes"`
+	Tags      map[string][]string `gorm:"type:text;serializer:json" json:"-"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
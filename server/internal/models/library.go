@@ -3,14 +3,20 @@ package models
 import (
 	"mime/multipart"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Song is a file of the library, a song in the trash is soft deleted so its history and playlists
+// survive until the trash is purged, its path can be taken again meanwhile
 type Song struct {
-	ID     uint      `gorm:"primaryKey" json:"id"`
-	UserId uint      `gorm:"not null;uniqueIndex:idx_song_user_path" json:"userId"`
-	Path   string    `gorm:"not null;uniqueIndex:idx_song_user_path" json:"path"`
-	Isrc   string    `gorm:"index" json:"isrc"`
-	MTime  time.Time `json:"mTime"`
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	UserId       uint           `gorm:"not null;uniqueIndex:idx_song_user_path_live,where:deleted_at IS NULL" json:"userId"`
+	Path         string         `gorm:"not null;uniqueIndex:idx_song_user_path_live,where:deleted_at IS NULL" json:"path"`
+	Isrc         string         `gorm:"index" json:"isrc"`
+	AudioQuality QualityLevel   `gorm:"index" json:"audioQuality"`
+	MTime        time.Time      `json:"mTime"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type RequestUploadSong struct {
@@ -29,6 +35,16 @@ type RequestUploadSong struct {
 	AlbumPeak    *float64              `form:"albumPeak"`
 	TrackGain    *float64              `form:"trackGain"`
 	TrackPeak    *float64              `form:"trackPeak"`
+	Genres       *[]string             `form:"genres"`
+	Label        *string               `form:"label"`
+	Copyright    *string               `form:"copyright"`
+	Barcode      *string               `form:"barcode"`
+	Composers    *[]string             `form:"composers"`
+	Lyricists    *[]string             `form:"lyricists"`
+	Bpm          *uint                 `form:"bpm"`
+	Key          *string               `form:"key"`
+	TrackTotal   *uint                 `form:"trackTotal"`
+	DiscTotal    *uint                 `form:"discTotal"`
 	ExtraTags    map[string][]string   `form:"extraTags"`
 }
 
@@ -47,6 +63,16 @@ type RequestEditSong struct {
 	AlbumPeak    *float64              `form:"albumPeak"`
 	TrackGain    *float64              `form:"trackGain"`
 	TrackPeak    *float64              `form:"trackPeak"`
+	Genres       *[]string             `form:"genres"`
+	Label        *string               `form:"label"`
+	Copyright    *string               `form:"copyright"`
+	Barcode      *string               `form:"barcode"`
+	Composers    *[]string             `form:"composers"`
+	Lyricists    *[]string             `form:"lyricists"`
+	Bpm          *uint                 `form:"bpm"`
+	Key          *string               `form:"key"`
+	TrackTotal   *uint                 `form:"trackTotal"`
+	DiscTotal    *uint                 `form:"discTotal"`
 	ExtraTags    map[string][]string   `form:"extraTags"`
 }
 
@@ -66,6 +92,23 @@ type ResponseSong struct {
 	AlbumPeak    float64  `json:"albumPeak"`
 	TrackGain    float64  `json:"trackGain"`
 	TrackPeak    float64  `json:"trackPeak"`
+	Genres       []string `json:"genres"`
+	Label        string   `json:"label"`
+	Copyright    string   `json:"copyright"`
+	Barcode      string   `json:"barcode"`
+	Composers    []string `json:"composers"`
+	Lyricists    []string `json:"lyricists"`
+	Bpm          uint     `json:"bpm"`
+	Key          string   `json:"key"`
+	TrackTotal   uint     `json:"trackTotal"`
+	DiscTotal    uint     `json:"discTotal"`
+
+	AudioQuality QualityLevel `json:"audioQuality"`
+}
+
+type ResponseLibraryQuality struct {
+	Quality QualityLevel `json:"quality"`
+	Count   int          `json:"count"`
 }
 
 type ResponseLibrary struct {
@@ -75,3 +118,11 @@ type ResponseLibrary struct {
 	Offset int            `json:"offset"`
 	Items  []ResponseSong `json:"items"`
 }
+
+// LibraryCover locates a cached cover to serve
+type LibraryCover struct {
+	Path    string
+	Mime    string
+	ETag    string
+	ModTime time.Time
+}
@@ -0,0 +1,41 @@
+package models
+
+type BulkOperation string
+
+const (
+	BulkSet      BulkOperation = "set"
+	BulkClear    BulkOperation = "clear"
+	BulkReplace  BulkOperation = "replace"
+	BulkRenumber BulkOperation = "renumber"
+)
+
+type RequestBulkOperation struct {
+	Op      BulkOperation `json:"op"`
+	Tag     string        `json:"tag"`
+	Values  []string      `json:"values"`
+	Find    string        `json:"find"`
+	Replace string        `json:"replace"`
+	Start   uint          `json:"start"`
+	PerDisc bool          `json:"perDisc"`
+}
+
+type RequestBulkAlbum struct {
+	AlbumArtist string `json:"albumArtist"`
+	Album       string `json:"album"`
+}
+
+type RequestBulkEdit struct {
+	Ids        []uint                 `json:"ids"`
+	Album      *RequestBulkAlbum      `json:"album"`
+	Operations []RequestBulkOperation `json:"operations"`
+}
+
+type BulkEditSong struct {
+	Id      uint   `json:"id"`
+	OldPath string `json:"oldPath"`
+	Path    string `json:"path"`
+}
+
+type ResponseBulkEdit struct {
+	Songs []BulkEditSong `json:"songs"`
+}
@@ -62,6 +62,46 @@ func (req RequestUploadSong) ToTags() map[string][]string {
 		tags[TagISRC] = []string{*req.Isrc}
 	}
 
+	if req.Genres != nil && len(*req.Genres) > 0 {
+		tags[TagGenre] = *req.Genres
+	}
+
+	if req.Label != nil {
+		tags[TagLabel] = []string{*req.Label}
+	}
+
+	if req.Copyright != nil {
+		tags[TagCopyright] = []string{*req.Copyright}
+	}
+
+	if req.Barcode != nil {
+		tags[TagBarcode] = []string{*req.Barcode}
+	}
+
+	if req.Composers != nil && len(*req.Composers) > 0 {
+		tags[TagComposer] = *req.Composers
+	}
+
+	if req.Lyricists != nil && len(*req.Lyricists) > 0 {
+		tags[TagLyricist] = *req.Lyricists
+	}
+
+	if req.Bpm != nil {
+		tags[TagBPM] = []string{strconv.FormatUint(uint64(*req.Bpm), 10)}
+	}
+
+	if req.Key != nil {
+		tags[TagKey] = []string{*req.Key}
+	}
+
+	if req.TrackTotal != nil {
+		tags[TagTrackTotal] = []string{strconv.FormatUint(uint64(*req.TrackTotal), 10)}
+	}
+
+	if req.DiscTotal != nil {
+		tags[TagDiscTotal] = []string{strconv.FormatUint(uint64(*req.DiscTotal), 10)}
+	}
+
 	maps.Copy(tags, req.ExtraTags)
 
 	return tags
@@ -124,6 +164,46 @@ func (req RequestEditSong) ToTags() map[string][]string {
 		tags[TagISRC] = []string{*req.Isrc}
 	}
 
+	if req.Genres != nil && len(*req.Genres) > 0 {
+		tags[TagGenre] = *req.Genres
+	}
+
+	if req.Label != nil {
+		tags[TagLabel] = []string{*req.Label}
+	}
+
+	if req.Copyright != nil {
+		tags[TagCopyright] = []string{*req.Copyright}
+	}
+
+	if req.Barcode != nil {
+		tags[TagBarcode] = []string{*req.Barcode}
+	}
+
+	if req.Composers != nil && len(*req.Composers) > 0 {
+		tags[TagComposer] = *req.Composers
+	}
+
+	if req.Lyricists != nil && len(*req.Lyricists) > 0 {
+		tags[TagLyricist] = *req.Lyricists
+	}
+
+	if req.Bpm != nil {
+		tags[TagBPM] = []string{strconv.FormatUint(uint64(*req.Bpm), 10)}
+	}
+
+	if req.Key != nil {
+		tags[TagKey] = []string{*req.Key}
+	}
+
+	if req.TrackTotal != nil {
+		tags[TagTrackTotal] = []string{strconv.FormatUint(uint64(*req.TrackTotal), 10)}
+	}
+
+	if req.DiscTotal != nil {
+		tags[TagDiscTotal] = []string{strconv.FormatUint(uint64(*req.DiscTotal), 10)}
+	}
+
 	maps.Copy(tags, req.ExtraTags)
 
 	return tags
@@ -14,4 +14,46 @@ const (
 	TagTrackGain    string = "REPLAYGAIN_TRACK_GAIN"
 	TagTrackPeak    string = "REPLAYGAIN_TRACK_PEAK"
 	TagISRC         string = "ISRC"
+	TagGenre        string = "GENRE"
+	TagLabel        string = "LABEL"
+	TagCopyright    string = "COPYRIGHT"
+	TagBarcode      string = "BARCODE"
+	TagComposer     string = "COMPOSER"
+	TagLyricist     string = "LYRICIST"
+	TagBPM          string = "BPM"
+	TagKey          string = "INITIALKEY"
+	TagTrackTotal   string = "TRACKTOTAL"
+	TagDiscTotal    string = "DISCTOTAL"
 )
+
+// TagProfile selects the tag layout written for a media server, the library itself always
+// reads the navidrome style keys above whatever the profile
+type TagProfile string
+
+const (
+	TagProfileNavidrome TagProfile = "navidrome"
+	TagProfileJellyfin  TagProfile = "jellyfin"
+	TagProfilePlex      TagProfile = "plex"
+	TagProfileKodi      TagProfile = "kodi"
+	TagProfileID3v23    TagProfile = "id3v23"
+)
+
+var TagProfiles = []TagProfile{TagProfileNavidrome, TagProfileJellyfin, TagProfilePlex, TagProfileKodi, TagProfileID3v23}
+
+func (p TagProfile) Valid() bool {
+	for _, profile := range TagProfiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+type RequestTagProfile struct {
+	Profile TagProfile `json:"profile"`
+}
+
+type ResponseTagProfile struct {
+	Profile  TagProfile   `json:"profile"`
+	Profiles []TagProfile `json:"profiles"`
+}
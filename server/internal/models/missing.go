@@ -0,0 +1,45 @@
+package models
+
+type MissingStatus string
+
+const (
+	MissingComplete MissingStatus = "complete"
+	MissingPartial  MissingStatus = "partial"
+	MissingAll      MissingStatus = "missing"
+)
+
+type MissingTrack struct {
+	Id           string `json:"id"`
+	Title        string `json:"title"`
+	TrackNumber  uint   `json:"trackNumber"`
+	VolumeNumber uint   `json:"volumeNumber"`
+	Isrc         string `json:"isrc"`
+}
+
+type MissingRelease struct {
+	FollowId    uint           `json:"followId"`
+	ArtistName  string         `json:"artistName"`
+	Provider    string         `json:"provider"`
+	Id          string         `json:"id"`
+	Type        ReleaseType    `json:"type"`
+	Title       string         `json:"title"`
+	ReleaseDate string         `json:"releaseDate"`
+	CoverUrl    string         `json:"coverUrl"`
+	Status      MissingStatus  `json:"status"`
+	Total       int            `json:"total"`
+	Owned       int            `json:"owned"`
+	Missing     []MissingTrack `json:"missing"`
+}
+
+type ResponseMissing struct {
+	Complete int              `json:"complete"`
+	Partial  int              `json:"partial"`
+	Missing  int              `json:"missing"`
+	Items    []MissingRelease `json:"items"`
+	Errors   []string         `json:"errors"`
+}
+
+type ResponseMissingQueue struct {
+	Albums int `json:"albums"`
+	Songs  int `json:"songs"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+type Playlist struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserId    uint           `gorm:"not null;uniqueIndex:idx_playlist_user_path" json:"userId"`
+	Name      string         `gorm:"not null" json:"name"`
+	Path      string         `gorm:"not null;uniqueIndex:idx_playlist_user_path" json:"path"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	Items     []PlaylistItem `gorm:"foreignKey:PlaylistId;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type PlaylistItem struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	PlaylistId uint `gorm:"not null;index" json:"playlistId"`
+	SongId     uint `gorm:"not null;index" json:"songId"`
+	Song       Song `gorm:"foreignKey:SongId;constraint:OnDelete:CASCADE" json:"-"`
+	Position   int  `gorm:"not null" json:"position"`
+}
+
+type RequestPlaylist struct {
+	Name string `json:"name"`
+}
+
+type RequestPlaylistSongs struct {
+	Ids []uint `json:"ids"`
+}
+
+// RequestPlaylistOrder lists every item id of the playlist in the new order
+type RequestPlaylistOrder struct {
+	Ids []uint `json:"ids"`
+}
+
+type RequestPlaylistImport struct {
+	File *multipart.FileHeader `form:"file"`
+	Name *string               `form:"name"`
+}
+
+type ResponsePlaylist struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Count     int64     `json:"count"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ResponsePlaylistItem struct {
+	ID       uint         `json:"id"`
+	Position int          `json:"position"`
+	Song     ResponseSong `json:"song"`
+}
+
+type ResponsePlaylistDetail struct {
+	ResponsePlaylist
+	Items []ResponsePlaylistItem `json:"items"`
+}
+
+type ResponsePlaylistImport struct {
+	Playlist  ResponsePlaylist `json:"playlist"`
+	Matched   int              `json:"matched"`
+	Unmatched []string         `json:"unmatched"`
+}
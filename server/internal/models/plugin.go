@@ -10,12 +10,12 @@ type Plugin interface {
 	Provider() string
 	Priority() int
 	Status(ctx context.Context, url string) error
-	Download(context.Context, uint, string) (io.ReadCloser, string, error)
+	Download(context.Context, uint, string, QualityPolicy) (io.ReadCloser, string, QualityLevel, error)
 	Song(context.Context, uint, string) (SongData, error)
 	Playlist(context.Context, uint, string) (PlaylistData, error)
 	Album(context.Context, uint, string) (AlbumData, error)
 	Artist(context.Context, uint, string) (ArtistData, error)
-	Search(context.Context, uint, string, string, string) (SearchData, error)
+	Search(context.Context, uint, SearchQuery) (SearchData, error)
 	Url(context.Context, uint, string) (UrlItem, error)
 	Lyrics(context.Context, uint, string) (string, string, error)
 }
@@ -43,6 +43,12 @@ type SongData struct {
 	Explicit        bool             `json:"explicit"`
 	Popularity      uint             `json:"popularity"`
 	Isrc            string           `json:"isrc"`
+	Genres          []string         `json:"genres"`
+	Copyright       string           `json:"copyright"`
+	Composers       []string         `json:"composers"`
+	Lyricists       []string         `json:"lyricists"`
+	Bpm             uint             `json:"bpm"`
+	Key             string           `json:"key"`
 	Artists         []SongDataArtist `json:"artists"`
 	Album           SongDataAlbum    `json:"album"`
 }
@@ -96,6 +102,10 @@ type AlbumData struct {
 	CoverUrl      string            `json:"coverUrl"`
 	AudioQuality  Quality           `json:"audioQuality"`
 	Explicit      bool              `json:"explicit"`
+	Genres        []string          `json:"genres"`
+	Label         string            `json:"label"`
+	Copyright     string            `json:"copyright"`
+	Upc           string            `json:"upc"`
 	Artists       []AlbumDataArtist `json:"artists"`
 	Songs         []AlbumDataSong   `json:"songs"`
 }
@@ -142,11 +152,29 @@ type ArtistDataAlbum struct {
 	Artists      []AlbumDataArtist `json:"artists"`
 }
 
+// SearchQuery holds one search per kind, an empty kind is not searched
+type SearchQuery struct {
+	Song     string
+	Album    string
+	Artist   string
+	Playlist string
+	Limit    uint
+	Offset   uint
+}
+
 type SearchData struct {
 	Songs     []SearchDataSong     `json:"songs"`
 	Albums    []SearchDataAlbum    `json:"albums"`
 	Artists   []SearchDataArtist   `json:"artists"`
 	Playlists []SearchDataPlaylist `json:"playlists"`
+	Total     SearchDataTotal      `json:"total"`
+}
+
+type SearchDataTotal struct {
+	Songs     uint `json:"songs"`
+	Albums    uint `json:"albums"`
+	Artists   uint `json:"artists"`
+	Playlists uint `json:"playlists"`
 }
 
 type SearchDataSong struct {
@@ -0,0 +1,80 @@
+package models
+
+import "slices"
+
+type QualityLevel string
+
+const (
+	QualityLow      QualityLevel = "LOW"
+	QualityHigh     QualityLevel = "HIGH"
+	QualityLossless QualityLevel = "LOSSLESS"
+	QualityHiRes    QualityLevel = "HIRES"
+)
+
+var QualityLevels = []QualityLevel{QualityHiRes, QualityLossless, QualityHigh, QualityLow}
+
+func (q QualityLevel) Rank() int {
+	switch q {
+	case QualityLow:
+		return 1
+	case QualityHigh:
+		return 2
+	case QualityLossless:
+		return 3
+	case QualityHiRes:
+		return 4
+	default:
+		return 0
+	}
+}
+
+func (q QualityLevel) Valid() bool {
+	return q.Rank() != 0
+}
+
+type QualityPolicy struct {
+	Preferences []QualityLevel `json:"preferences"`
+	Minimum     QualityLevel   `json:"minimum"`
+	Fallback    bool           `json:"fallback"`
+}
+
+func (p QualityPolicy) Accept(requested QualityLevel, received QualityLevel) bool {
+	if received.Rank() < p.Minimum.Rank() {
+		return false
+	}
+	if received == requested {
+		return true
+	}
+	return p.Fallback && slices.Contains(p.Preferences, received)
+}
+
+// Above restricts the policy to the qualities strictly better than the given one
+func (p QualityPolicy) Above(quality QualityLevel) QualityPolicy {
+	above := QualityPolicy{
+		Preferences: make([]QualityLevel, 0, len(p.Preferences)),
+		Minimum:     p.Minimum,
+		Fallback:    p.Fallback,
+	}
+	for _, preference := range p.Preferences {
+		if preference.Rank() > quality.Rank() {
+			above.Preferences = append(above.Preferences, preference)
+		}
+	}
+	for _, level := range QualityLevels {
+		if level.Rank() == quality.Rank()+1 && level.Rank() > above.Minimum.Rank() {
+			above.Minimum = level
+		}
+	}
+	return above
+}
+
+// Best returns the highest quality of the preference ladder
+func (p QualityPolicy) Best() QualityLevel {
+	var best QualityLevel
+	for _, preference := range p.Preferences {
+		if preference.Rank() > best.Rank() {
+			best = preference
+		}
+	}
+	return best
+}
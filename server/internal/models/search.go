@@ -0,0 +1,59 @@
+package models
+
+type UnifiedSource struct {
+	Provider     string `json:"provider"`
+	Id           string `json:"id"`
+	AudioQuality string `json:"audioQuality"`
+}
+
+type UnifiedLocal struct {
+	SongId       uint         `json:"songId"`
+	Path         string       `json:"path"`
+	AudioQuality QualityLevel `json:"audioQuality"`
+}
+
+type UnifiedSong struct {
+	Isrc     string          `json:"isrc"`
+	Title    string          `json:"title"`
+	Artists  []string        `json:"artists"`
+	Album    string          `json:"album"`
+	Duration uint            `json:"duration"`
+	CoverUrl string          `json:"coverUrl"`
+	Explicit bool            `json:"explicit"`
+	Score    float64         `json:"score"`
+	Sources  []UnifiedSource `json:"sources"`
+	Local    []UnifiedLocal  `json:"local"`
+}
+
+type UnifiedAlbum struct {
+	Title    string          `json:"title"`
+	Artists  []string        `json:"artists"`
+	CoverUrl string          `json:"coverUrl"`
+	Explicit bool            `json:"explicit"`
+	Score    float64         `json:"score"`
+	Sources  []UnifiedSource `json:"sources"`
+	Local    []UnifiedLocal  `json:"local"`
+}
+
+type UnifiedArtist struct {
+	Name       string          `json:"name"`
+	PictureUrl string          `json:"pictureUrl"`
+	Score      float64         `json:"score"`
+	Sources    []UnifiedSource `json:"sources"`
+}
+
+type UnifiedTotal struct {
+	Songs   int `json:"songs"`
+	Albums  int `json:"albums"`
+	Artists int `json:"artists"`
+}
+
+type ResponseUnifiedSearch struct {
+	Query   string          `json:"query"`
+	Limit   uint            `json:"limit"`
+	Offset  uint            `json:"offset"`
+	Total   UnifiedTotal    `json:"total"`
+	Songs   []UnifiedSong   `json:"songs"`
+	Albums  []UnifiedAlbum  `json:"albums"`
+	Artists []UnifiedArtist `json:"artists"`
+}
@@ -0,0 +1,34 @@
+package models
+
+type Settings struct {
+	ID                  bool   `gorm:"primaryKey;default:true" json:"-"`
+	DownloadGlobalLimit uint   `gorm:"not null;default:3" json:"downloadGlobalLimit"`
+	DownloadUserLimit   uint   `gorm:"not null;default:3" json:"downloadUserLimit"`
+	DownloadRetryLimit  uint   `gorm:"not null;default:3" json:"downloadRetryLimit"`
+	DownloadRetryDelay  uint   `gorm:"not null;default:30" json:"downloadRetryDelay"`
+	AutoFetchSchedule   string `gorm:"not null;default:'0 1 * * *'" json:"autoFetchSchedule"`
+	CacheSongTTL        uint   `gorm:"not null;default:86400" json:"cacheSongTTL"`
+	CacheAlbumTTL       uint   `gorm:"not null;default:86400" json:"cacheAlbumTTL"`
+	CacheArtistTTL      uint   `gorm:"not null;default:3600" json:"cacheArtistTTL"`
+	CachePlaylistTTL    uint   `gorm:"not null;default:900" json:"cachePlaylistTTL"`
+	CoverMaxSize        uint   `gorm:"not null;default:0" json:"coverMaxSize"`
+	CoverFolderArt      bool   `gorm:"not null;default:true" json:"coverFolderArt"`
+	CoverArtistArt      bool   `gorm:"not null;default:true" json:"coverArtistArt"`
+	TrashRetentionDays  uint   `gorm:"not null;default:30" json:"trashRetentionDays"`
+}
+
+type RequestSettings struct {
+	DownloadGlobalLimit *uint   `json:"downloadGlobalLimit"`
+	DownloadUserLimit   *uint   `json:"downloadUserLimit"`
+	DownloadRetryLimit  *uint   `json:"downloadRetryLimit"`
+	DownloadRetryDelay  *uint   `json:"downloadRetryDelay"`
+	AutoFetchSchedule   *string `json:"autoFetchSchedule"`
+	CacheSongTTL        *uint   `json:"cacheSongTTL"`
+	CacheAlbumTTL       *uint   `json:"cacheAlbumTTL"`
+	CacheArtistTTL      *uint   `json:"cacheArtistTTL"`
+	CachePlaylistTTL    *uint   `json:"cachePlaylistTTL"`
+	CoverMaxSize        *uint   `json:"coverMaxSize"`
+	CoverFolderArt      *bool   `json:"coverFolderArt"`
+	CoverArtistArt      *bool   `json:"coverArtistArt"`
+	TrashRetentionDays  *uint   `json:"trashRetentionDays"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// SubscriptionSong keeps the artists and title of a song so it is found in the library without an isrc.
+// SongId links the library song once found or downloaded, Queued marks the songs the subscription
+// downloaded itself, the only ones it may drop from the library
+type SubscriptionSong struct {
+	Id     string `json:"id"`
+	Isrc   string `json:"isrc"`
+	Artist string `json:"artist"`
+	Title  string `json:"title"`
+	SongId uint   `json:"songId"`
+	Queued bool   `json:"queued"`
+}
+
+// PlaylistSubscription keeps a provider playlist in sync with the library,
+// Songs is the content of the playlist at the last sync
+type PlaylistSubscription struct {
+	ID           uint               `gorm:"primaryKey" json:"id"`
+	UserId       uint               `gorm:"not null;uniqueIndex:idx_subscription;uniqueIndex:idx_subscription_path" json:"userId"`
+	Provider     string             `gorm:"not null;uniqueIndex:idx_subscription" json:"provider"`
+	PlaylistId   string             `gorm:"not null;uniqueIndex:idx_subscription" json:"playlistId"`
+	Title        string             `gorm:"not null" json:"title"`
+	CoverUrl     string             `gorm:"not null" json:"coverUrl"`
+	Path         string             `gorm:"not null;uniqueIndex:idx_subscription_path" json:"path"`
+	DropRemoved  bool               `gorm:"not null;default:false" json:"dropRemoved"`
+	Songs        []SubscriptionSong `gorm:"type:text;serializer:json" json:"-"`
+	LastSyncedAt *time.Time         `json:"lastSyncedAt"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+type RequestSubscription struct {
+	Provider    string `json:"provider"`
+	Id          string `json:"id"`
+	DropRemoved bool   `json:"dropRemoved"`
+}
+
+type RequestSubscriptionUpdate struct {
+	DropRemoved bool `json:"dropRemoved"`
+}
+
+type ResponseSubscriptionSync struct {
+	Added   int `json:"added"`
+	Queued  int `json:"queued"`
+	Removed int `json:"removed"`
+	Dropped int `json:"dropped"`
+}
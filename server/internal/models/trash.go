@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TrashItem marks a song of the trash, its file waits in the trash folder of the user until it is
+// restored or purged
+type TrashItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserId    uint      `gorm:"not null;index" json:"-"`
+	SongId    uint      `gorm:"not null;uniqueIndex" json:"songId"`
+	Song      Song      `gorm:"foreignKey:SongId;constraint:OnDelete:CASCADE" json:"-"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `gorm:"not null;index" json:"deletedAt"`
+}
+
+type ResponseTrashItem struct {
+	TrashItem
+	Path         string       `json:"path"`
+	Isrc         string       `json:"isrc"`
+	AudioQuality QualityLevel `json:"audioQuality"`
+	PurgeAt      *time.Time   `json:"purgeAt"`
+}
+
+type ResponseTrash struct {
+	Items         []ResponseTrashItem `json:"items"`
+	RetentionDays uint                `json:"retentionDays"`
+}
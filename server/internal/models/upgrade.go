@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type UpgradeCandidate struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	UserId     uint         `gorm:"not null;uniqueIndex:idx_upgrade_user_song" json:"userId"`
+	SongId     uint         `gorm:"not null;uniqueIndex:idx_upgrade_user_song" json:"songId"`
+	Song       Song         `gorm:"foreignKey:SongId;constraint:OnDelete:CASCADE" json:"-"`
+	Path       string       `json:"path"`
+	Isrc       string       `json:"isrc"`
+	Codec      string       `json:"codec"`
+	SampleRate uint         `json:"sampleRate"`
+	Bitrate    uint         `json:"bitrate"`
+	Current    QualityLevel `json:"current"`
+	Provider   string       `json:"provider"`
+	ProviderId string       `json:"providerId"`
+	Available  QualityLevel `json:"available"`
+	ScannedAt  time.Time    `json:"scannedAt"`
+}
+
+type RequestUpgrade struct {
+	Ids []uint `json:"ids"`
+}
+
+type ResponseUpgrades struct {
+	Scanning bool               `json:"scanning"`
+	Items    []UpgradeCandidate `json:"items"`
+}
@@ -1,14 +1,31 @@
 package models
 
 type User struct {
-	ID        uint        `gorm:"primaryKey" json:"id"`
-	Username  string      `gorm:"not null;uniqueIndex" json:"username"`
-	Password  string      `gorm:"not null" json:"password"`
-	HiRes     bool        `gorm:"default:false" json:"hiRes"`
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Username string `gorm:"not null;uniqueIndex" json:"username"`
+	Password string `gorm:"not null" json:"password"`
+	HiRes    bool   `gorm:"default:false" json:"hiRes"`
+
+	QualityPreferences []QualityLevel `gorm:"type:text;serializer:json" json:"qualityPreferences"`
+	QualityMinimum     QualityLevel   `gorm:"not null;default:'LOW'" json:"qualityMinimum"`
+	QualityFallback    bool           `gorm:"not null;default:true" json:"qualityFallback"`
+
+	AutoFetchSchedule string     `gorm:"not null;default:''" json:"autoFetchSchedule"`
+	TagProfile        TagProfile `gorm:"not null;default:'navidrome'" json:"tagProfile"`
+	FeedToken         *string    `gorm:"uniqueIndex" json:"-"`
+
 	Sessions  UserSession `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"sessions"`
 	Follows   Follow      `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"follows"`
 	Instances Instance    `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"instances"`
 	Songs     Song        `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"songs"`
+	History   SongHistory `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"history"`
+	Trash     TrashItem   `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"trash"`
+
+	AutoFetchRuns AutoFetchRun `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"autoFetchRuns"`
+	Feed          FeedEntry    `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"feed"`
+
+	Subscriptions PlaylistSubscription `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"subscriptions"`
+	Playlists     Playlist             `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE" json:"playlists"`
 }
 
 type RequestUserLogin struct {
@@ -26,3 +43,28 @@ type ResponseUser struct {
 	Username string `json:"username"`
 	HiRes    bool   `json:"hiRes"`
 }
+
+type RequestQualityPolicy = QualityPolicy
+
+// QualityPolicy falls back on the HiRes switch while the user never set a preference ladder
+func (u User) QualityPolicy() QualityPolicy {
+	preferences := u.QualityPreferences
+	if len(preferences) == 0 {
+		if u.HiRes {
+			preferences = []QualityLevel{QualityHiRes, QualityLossless, QualityHigh, QualityLow}
+		} else {
+			preferences = []QualityLevel{QualityLossless, QualityHigh, QualityLow}
+		}
+	}
+
+	minimum := u.QualityMinimum
+	if !minimum.Valid() {
+		minimum = QualityLow
+	}
+
+	return QualityPolicy{
+		Preferences: preferences,
+		Minimum:     minimum,
+		Fallback:    u.QualityFallback,
+	}
+}
@@ -0,0 +1,202 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+const (
+	cacheMemoryLimit   = 4096
+	cachePurgeEvery    = time.Hour
+	defaultSongTTL     = 24 * time.Hour
+	defaultAlbumTTL    = 24 * time.Hour
+	defaultArtistTTL   = time.Hour
+	defaultPlaylistTTL = 15 * time.Minute
+)
+
+type cacheKey struct {
+	provider string
+	kind     models.Type
+	id       string
+}
+
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// metadataCache keeps the provider responses in memory in front of the database,
+// entries are stored encoded so every caller gets its own copy
+type metadataCache struct {
+	mu        sync.Mutex
+	entries   map[cacheKey]cacheEntry
+	ttl       map[models.Type]time.Duration
+	stats     map[models.Type]models.CacheKindStats
+	lastPurge time.Time
+}
+
+var Cache = metadataCache{
+	entries: make(map[cacheKey]cacheEntry),
+	ttl: map[models.Type]time.Duration{
+		models.TypeSong:     defaultSongTTL,
+		models.TypeAlbum:    defaultAlbumTTL,
+		models.TypeArtist:   defaultArtistTTL,
+		models.TypePlaylist: defaultPlaylistTTL,
+	},
+	stats: make(map[models.Type]models.CacheKindStats),
+}
+
+type noCacheKey struct{}
+
+// WithoutCache makes the helpers skip the cached value and refresh it, for the callers that need the
+// current state of the provider such as the subscription sync
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func (c *metadataCache) ApplySettings(settings *models.Settings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl[models.TypeSong] = time.Duration(settings.CacheSongTTL) * time.Second
+	c.ttl[models.TypeAlbum] = time.Duration(settings.CacheAlbumTTL) * time.Second
+	c.ttl[models.TypeArtist] = time.Duration(settings.CacheArtistTTL) * time.Second
+	c.ttl[models.TypePlaylist] = time.Duration(settings.CachePlaylistTTL) * time.Second
+}
+
+func (c *metadataCache) count(kind models.Type, update func(*models.CacheKindStats)) {
+	stats := c.stats[kind]
+	update(&stats)
+	c.stats[kind] = stats
+}
+
+func (c *metadataCache) load(key cacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	if c.ttl[key.kind] <= 0 {
+		c.mu.Unlock()
+		return nil, false
+	}
+	if entry, ok := c.entries[key]; ok {
+		if time.Now().Before(entry.expires) {
+			c.count(key.kind, func(s *models.CacheKindStats) { s.MemoryHits++ })
+			c.mu.Unlock()
+			return entry.data, true
+		}
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	entry, err := repository.GetMetadataCache(key.provider, key.kind, key.id)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.count(key.kind, func(s *models.CacheKindStats) { s.Misses++ })
+		return nil, false
+	}
+	c.count(key.kind, func(s *models.CacheKindStats) { s.DatabaseHits++ })
+	c.remember(key, cacheEntry{data: entry.Data, expires: entry.ExpiresAt})
+	return entry.Data, true
+}
+
+// remember keeps an entry in memory, the expired entries are evicted first when the memory tier is full
+func (c *metadataCache) remember(key cacheKey, entry cacheEntry) {
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= cacheMemoryLimit {
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < cacheMemoryLimit {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
+
+func (c *metadataCache) store(key cacheKey, data []byte) {
+	c.mu.Lock()
+	ttl := c.ttl[key.kind]
+	if ttl <= 0 {
+		c.mu.Unlock()
+		return
+	}
+	expires := time.Now().Add(ttl)
+	c.remember(key, cacheEntry{data: data, expires: expires})
+	purge := time.Since(c.lastPurge) > cachePurgeEvery
+	if purge {
+		c.lastPurge = time.Now()
+	}
+	c.mu.Unlock()
+
+	if err := repository.SaveMetadataCache(models.MetadataCache{Provider: key.provider, Kind: key.kind, EntityId: key.id, Data: data, ExpiresAt: expires}); err != nil {
+		log.Println("plugins.metadataCache.store:", err)
+	}
+	if purge {
+		if err := repository.DeleteExpiredMetadataCache(); err != nil {
+			log.Println("plugins.metadataCache.store:", err)
+		}
+	}
+}
+
+// Invalidate drops the entries matching the non empty filters from both tiers
+func (c *metadataCache) Invalidate(provider string, kind models.Type, id string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if (provider == "" || key.provider == provider) && (kind == "" || key.kind == kind) && (id == "" || key.id == id) {
+			delete(c.entries, key)
+		}
+	}
+	return repository.DeleteMetadataCache(provider, kind, id)
+}
+
+func (c *metadataCache) Stats() (models.ResponseCacheStats, error) {
+	c.mu.Lock()
+	stats := models.ResponseCacheStats{
+		Kinds:         make(map[models.Type]models.CacheKindStats),
+		MemoryEntries: len(c.entries),
+	}
+	for _, kind := range []models.Type{models.TypeSong, models.TypeAlbum, models.TypeArtist, models.TypePlaylist} {
+		stats.Kinds[kind] = c.stats[kind]
+	}
+	c.mu.Unlock()
+
+	count, err := repository.CountMetadataCache()
+	if err != nil {
+		return models.ResponseCacheStats{}, err
+	}
+	stats.DatabaseEntries = count
+	return stats, nil
+}
+
+// cached returns the cached value of an entity or fetches and caches it, errors are never cached
+func cached[T any](ctx context.Context, provider string, kind models.Type, id string, fetch func() (T, error)) (T, error) {
+	key := cacheKey{provider: provider, kind: kind, id: id}
+	if skip, _ := ctx.Value(noCacheKey{}).(bool); !skip {
+		if data, ok := Cache.load(key); ok {
+			var value T
+			if err := json.Unmarshal(data, &value); err == nil {
+				return value, nil
+			}
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	if data, err := json.Marshal(value); err == nil {
+		Cache.store(key, data)
+	}
+	return value, nil
+}
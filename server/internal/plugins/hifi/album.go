@@ -3,7 +3,6 @@ package hifi
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -26,7 +25,7 @@ func fetchAlbum(ctx context.Context, url2 string, id string) (albumData, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return albumData{}, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+		return albumData{}, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data albumData
@@ -88,6 +87,9 @@ func (p *Hifi) Album(ctx context.Context, userId uint, id string) (models.AlbumD
 		NumberVolumes: data.Data.NumberOfVolumes,
 		CoverUrl:      hifi_utils.GetImageURL(data.Data.CoverUrl, 640),
 		Explicit:      data.Data.Explicit,
+		Genres:        make([]string, 0),
+		Copyright:     data.Data.Copyright,
+		Upc:           data.Data.Upc,
 		Songs:         make([]models.AlbumDataSong, 0),
 		Artists:       make([]models.AlbumDataArtist, 0),
 	}
@@ -3,7 +3,6 @@ package hifi
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"slices"
@@ -28,7 +27,7 @@ func fetchArtistInfo(ctx context.Context, url2 string, id string) (artistInfo, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return artistInfo{}, fmt.Errorf("fetchArtistInfo: http: %w", errors.New(resp.Status))
+		return artistInfo{}, fmt.Errorf("fetchArtistInfo: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data artistInfo
@@ -79,7 +78,7 @@ func fetchArtistAlbums(ctx context.Context, url string, id string) (artistAlbums
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return artistAlbums{}, fmt.Errorf("fetchArtistAlbums: http: %w", errors.New(resp.Status))
+		return artistAlbums{}, fmt.Errorf("fetchArtistAlbums: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data artistAlbums
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"net/url"
 	"os/exec"
 	"strconv"
@@ -36,7 +37,7 @@ func fetchDownloadInfo(ctx context.Context, url2 string, id string, quality stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return downloadData{}, fmt.Errorf("fetchDownloadInfo: http: %w", errors.New(resp.Status))
+		return downloadData{}, fmt.Errorf("fetchDownloadInfo: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data downloadData
@@ -92,7 +93,7 @@ func downloadTidal(ctx context.Context, manifestRaw []byte) (io.ReadCloser, erro
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("downloadTidal: http: %w", errors.New(resp.Status))
+		return nil, fmt.Errorf("downloadTidal: http: %w", utils.NewStatusError(resp))
 	}
 
 	return resp.Body, nil
@@ -137,7 +138,7 @@ func downloadMPD(ctx context.Context, manifest []byte) (io.ReadCloser, error) {
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			defer resp.Body.Close()
-			return nil, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+			return nil, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 		}
 
 		readers = append(readers, resp.Body)
@@ -198,27 +199,26 @@ func remuxM4AtoFLAC(reader io.ReadCloser) (io.ReadCloser, error) {
 	return newReader, nil
 }
 
-func (p *Hifi) Download(ctx context.Context, userId uint, id string) (io.ReadCloser, string, error) {
-	quality := "HI_RES_LOSSLESS"
-	if user, err := repository.GetUserByID(userId); err != nil {
-		return nil, "", fmt.Errorf("Hifi.Download: %w", err)
-	} else if !user.HiRes {
-		quality = "LOSSLESS"
-	}
-
-	instances, err := repository.ListInstancesByUserIDByAPI(userId, p.Name())
-	if err != nil {
-		return nil, "", fmt.Errorf("Hifi.Download: %w", err)
-	}
+var hifiQualities = map[models.QualityLevel]string{
+	models.QualityHiRes:    "HI_RES_LOSSLESS",
+	models.QualityLossless: "LOSSLESS",
+	models.QualityHigh:     "HIGH",
+	models.QualityLow:      "LOW",
+}
 
-	info, err := getDownloadInfo(ctx, instances, id, quality)
-	if err != nil {
-		return nil, "", fmt.Errorf("Hifi.Download: %w", err)
+func qualityLevel(audioQuality string) models.QualityLevel {
+	for level, quality := range hifiQualities {
+		if quality == audioQuality {
+			return level
+		}
 	}
+	return ""
+}
 
+func (p *Hifi) openStream(ctx context.Context, info downloadData) (io.ReadCloser, string, error) {
 	manifest, err := base64.StdEncoding.DecodeString(info.Data.Manifest)
 	if err != nil {
-		return nil, "", fmt.Errorf("Hifi.Download: base64.StdEncoding.DecodeString: %w", err)
+		return nil, "", fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
 	}
 
 	var reader io.ReadCloser
@@ -231,25 +231,65 @@ func (p *Hifi) Download(ctx context.Context, userId uint, id string) (io.ReadClo
 		err = errors.New("manifest type unknown")
 	}
 	if err != nil {
-		return nil, "", fmt.Errorf("Hifi.Download: %w", err)
+		return nil, "", err
 	}
 
 	var extension string
 	switch info.Data.AudioQuality {
 	case "HI_RES_LOSSLESS":
-		if quality != "HI_RES_LOSSLESS" {
-			return nil, "", fmt.Errorf("Hifi.Download: %w", errors.New("audio quality received not conform"))
-		}
 		reader, err = remuxM4AtoFLAC(reader)
 		if err != nil {
-			return nil, "", fmt.Errorf("Hifi.Download: %w", err)
+			return nil, "", err
 		}
 		extension = "flac"
 	case "LOSSLESS":
 		extension = "flac"
-	case "HIGH":
+	case "HIGH", "LOW":
 		extension = "m4a"
 	}
 
 	return reader, extension, nil
 }
+
+// Download walks the preference ladder of the policy, a rung answered with another quality is kept
+// when the fallback allows it, otherwise the next rung is requested. Only a quality the song is not
+// offered in moves down the ladder, any other error ends the attempt
+func (p *Hifi) Download(ctx context.Context, userId uint, id string, policy models.QualityPolicy) (io.ReadCloser, string, models.QualityLevel, error) {
+	instances, err := repository.ListInstancesByUserIDByAPI(userId, p.Name())
+	if err != nil {
+		return nil, "", "", fmt.Errorf("Hifi.Download: %w", err)
+	}
+
+	for i, requested := range policy.Preferences {
+		if i > 0 && !policy.Fallback {
+			break
+		}
+
+		info, err := getDownloadInfo(ctx, instances, id, hifiQualities[requested])
+		if err != nil {
+			var statusErr *utils.StatusError
+			// a 404 can come from a single broken instance, only a refusal tells the song is region locked
+			if errors.As(err, &statusErr) && (statusErr.Code == http.StatusForbidden || statusErr.Code == http.StatusUnavailableForLegalReasons) {
+				return nil, "", "", fmt.Errorf("Hifi.Download: %w: %w", models.ErrNotAvailable, err)
+			}
+			if ctx.Err() != nil {
+				return nil, "", "", fmt.Errorf("Hifi.Download: %w", ctx.Err())
+			}
+			// a network or instance error is left to the retry policy instead of settling for a lower rung
+			return nil, "", "", fmt.Errorf("Hifi.Download: %w", err)
+		}
+
+		received := qualityLevel(info.Data.AudioQuality)
+		if !policy.Accept(requested, received) {
+			continue
+		}
+
+		reader, extension, err := p.openStream(ctx, info)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("Hifi.Download: %w", err)
+		}
+		return reader, extension, received, nil
+	}
+
+	return nil, "", "", fmt.Errorf("Hifi.Download: %w", models.ErrQualityMismatch)
+}
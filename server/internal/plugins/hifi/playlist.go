@@ -3,7 +3,6 @@ package hifi
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -26,7 +25,7 @@ func fetchPlaylist(ctx context.Context, apiURL string, id string) (playlistData,
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return playlistData{}, fmt.Errorf("fetchPlaylist: http: %w", errors.New(resp.Status))
+		return playlistData{}, fmt.Errorf("fetchPlaylist: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data playlistData
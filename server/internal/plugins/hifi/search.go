@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -15,18 +16,18 @@ import (
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
 )
 
-func fetchSearchSong(ctx context.Context, url2 string, song string) (searchSongData, error) {
+func fetchSearchSong(ctx context.Context, url2 string, song string, limit uint, offset uint) (searchSongData, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	resp, err := utils.Fetch(ctx, url2+"/search/?s="+url.QueryEscape(song))
+	resp, err := utils.Fetch(ctx, url2+"/search/?"+searchParams("s", song, limit, offset))
 	if err != nil {
 		return searchSongData{}, fmt.Errorf("fetchAlbum: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return searchSongData{}, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+		return searchSongData{}, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data searchSongData
@@ -37,7 +38,7 @@ func fetchSearchSong(ctx context.Context, url2 string, song string) (searchSongD
 	return data, nil
 }
 
-func getSearchSong(ctx context.Context, instances []models.Instance, song string) (searchSongData, error) {
+func getSearchSong(ctx context.Context, instances []models.Instance, song string, limit uint, offset uint) (searchSongData, error) {
 	type res struct {
 		data searchSongData
 		err  error
@@ -46,7 +47,7 @@ func getSearchSong(ctx context.Context, instances []models.Instance, song string
 	ch := make(chan res, len(instances))
 	for _, instance := range instances {
 		go func(url string) {
-			data, err := fetchSearchSong(ctx, url, song)
+			data, err := fetchSearchSong(ctx, url, song, limit, offset)
 			ch <- res{data: data, err: err}
 		}(instance.Url)
 	}
@@ -66,18 +67,18 @@ func getSearchSong(ctx context.Context, instances []models.Instance, song string
 	return searchSongData{}, fmt.Errorf("getSearchSong: %w", lastErr)
 }
 
-func fetchSearchAlbum(ctx context.Context, url2 string, album string) (searchAlbumData, error) {
+func fetchSearchAlbum(ctx context.Context, url2 string, album string, limit uint, offset uint) (searchAlbumData, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	resp, err := utils.Fetch(ctx, url2+"/search/?al="+url.QueryEscape(album))
+	resp, err := utils.Fetch(ctx, url2+"/search/?"+searchParams("al", album, limit, offset))
 	if err != nil {
 		return searchAlbumData{}, fmt.Errorf("fetchAlbum: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return searchAlbumData{}, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+		return searchAlbumData{}, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data searchAlbumData
@@ -88,7 +89,7 @@ func fetchSearchAlbum(ctx context.Context, url2 string, album string) (searchAlb
 	return data, nil
 }
 
-func getSearchAlbum(ctx context.Context, instances []models.Instance, album string) (searchAlbumData, error) {
+func getSearchAlbum(ctx context.Context, instances []models.Instance, album string, limit uint, offset uint) (searchAlbumData, error) {
 	type res struct {
 		data searchAlbumData
 		err  error
@@ -97,7 +98,7 @@ func getSearchAlbum(ctx context.Context, instances []models.Instance, album stri
 	ch := make(chan res, len(instances))
 	for _, instance := range instances {
 		go func(url string) {
-			data, err := fetchSearchAlbum(ctx, url, album)
+			data, err := fetchSearchAlbum(ctx, url, album, limit, offset)
 			ch <- res{data: data, err: err}
 		}(instance.Url)
 	}
@@ -117,18 +118,18 @@ func getSearchAlbum(ctx context.Context, instances []models.Instance, album stri
 	return searchAlbumData{}, fmt.Errorf("getSearchAlbum: %w", lastErr)
 }
 
-func fetchSearchArtist(ctx context.Context, url2 string, artist string) (searchArtistData, error) {
+func fetchSearchArtist(ctx context.Context, url2 string, artist string, limit uint, offset uint) (searchArtistData, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	resp, err := utils.Fetch(ctx, url2+"/search/?a="+url.QueryEscape(artist))
+	resp, err := utils.Fetch(ctx, url2+"/search/?"+searchParams("a", artist, limit, offset))
 	if err != nil {
 		return searchArtistData{}, fmt.Errorf("fetchAlbum: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return searchArtistData{}, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+		return searchArtistData{}, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data searchArtistData
@@ -139,7 +140,7 @@ func fetchSearchArtist(ctx context.Context, url2 string, artist string) (searchA
 	return data, nil
 }
 
-func getSearchArtist(ctx context.Context, instances []models.Instance, artist string) (searchArtistData, error) {
+func getSearchArtist(ctx context.Context, instances []models.Instance, artist string, limit uint, offset uint) (searchArtistData, error) {
 	type res struct {
 		data searchArtistData
 		err  error
@@ -148,7 +149,7 @@ func getSearchArtist(ctx context.Context, instances []models.Instance, artist st
 	ch := make(chan res, len(instances))
 	for _, instance := range instances {
 		go func(url string) {
-			data, err := fetchSearchArtist(ctx, url, artist)
+			data, err := fetchSearchArtist(ctx, url, artist, limit, offset)
 			ch <- res{data: data, err: err}
 		}(instance.Url)
 	}
@@ -168,18 +169,18 @@ func getSearchArtist(ctx context.Context, instances []models.Instance, artist st
 	return searchArtistData{}, fmt.Errorf("getSearchArtist: %w", lastErr)
 }
 
-func fetchSearchPlaylist(ctx context.Context, apiURL string, album string) (searchPlaylistData, error) {
+func fetchSearchPlaylist(ctx context.Context, apiURL string, playlist string, limit uint, offset uint) (searchPlaylistData, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	resp, err := utils.Fetch(ctx, apiURL+"/search/?p="+url.QueryEscape(album))
+	resp, err := utils.Fetch(ctx, apiURL+"/search/?"+searchParams("p", playlist, limit, offset))
 	if err != nil {
 		return searchPlaylistData{}, fmt.Errorf("fetchSearchPlaylist: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return searchPlaylistData{}, fmt.Errorf("fetchSearchPlaylist: http: %w", errors.New(resp.Status))
+		return searchPlaylistData{}, fmt.Errorf("fetchSearchPlaylist: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data searchPlaylistData
@@ -190,7 +191,7 @@ func fetchSearchPlaylist(ctx context.Context, apiURL string, album string) (sear
 	return data, nil
 }
 
-func getSearchPlaylist(ctx context.Context, instances []models.Instance, album string) (searchPlaylistData, error) {
+func getSearchPlaylist(ctx context.Context, instances []models.Instance, playlist string, limit uint, offset uint) (searchPlaylistData, error) {
 	type res struct {
 		data searchPlaylistData
 		err  error
@@ -199,7 +200,7 @@ func getSearchPlaylist(ctx context.Context, instances []models.Instance, album s
 	ch := make(chan res, len(instances))
 	for _, instance := range instances {
 		go func(url string) {
-			data, err := fetchSearchPlaylist(ctx, url, album)
+			data, err := fetchSearchPlaylist(ctx, url, playlist, limit, offset)
 			ch <- res{data: data, err: err}
 		}(instance.Url)
 	}
@@ -216,55 +217,69 @@ func getSearchPlaylist(ctx context.Context, instances []models.Instance, album s
 			return searchPlaylistData{}, ctx.Err()
 		}
 	}
-	return searchPlaylistData{}, fmt.Errorf("getSearchAlbum: %w", lastErr)
+	return searchPlaylistData{}, fmt.Errorf("getSearchPlaylist: %w", lastErr)
 }
 
-func getSearchData(ctx context.Context, instances []models.Instance, song, album, artist string) (searchSongData, searchAlbumData, searchArtistData, searchPlaylistData, error) {
-	type res struct {
-		data any
-		err  error
-	}
-
-	ch := make(chan res, 4)
-	go func() {
-		data, err := getSearchSong(ctx, instances, song)
-		ch <- res{data: data, err: err}
-	}()
-	go func() {
-		data, err := getSearchAlbum(ctx, instances, album)
-		ch <- res{data: data, err: err}
-	}()
-	go func() {
-		data, err := getSearchArtist(ctx, instances, artist)
-		ch <- res{data: data, err: err}
-	}()
-	go func() {
-		data, err := getSearchPlaylist(ctx, instances, artist)
-		ch <- res{data: data, err: err}
-	}()
-
+// getSearchData only queries the kinds with a non empty search
+func getSearchData(ctx context.Context, instances []models.Instance, query models.SearchQuery) (searchSongData, searchAlbumData, searchArtistData, searchPlaylistData) {
 	var songData searchSongData
 	var albumData searchAlbumData
 	var artistData searchArtistData
 	var playlistData searchPlaylistData
-	for range 4 {
-		res := <-ch
-		switch v := res.data.(type) {
-		case searchSongData:
-			songData = v
-		case searchAlbumData:
-			albumData = v
-		case searchArtistData:
-			artistData = v
-		case searchPlaylistData:
-			playlistData = v
-		}
+
+	var wg sync.WaitGroup
+	if query.Song != "" {
+		wg.Go(func() {
+			songData, _ = getSearchSong(ctx, instances, query.Song, query.Limit, query.Offset)
+		})
+	}
+	if query.Album != "" {
+		wg.Go(func() {
+			albumData, _ = getSearchAlbum(ctx, instances, query.Album, query.Limit, query.Offset)
+		})
+	}
+	if query.Artist != "" {
+		wg.Go(func() {
+			artistData, _ = getSearchArtist(ctx, instances, query.Artist, query.Limit, query.Offset)
+		})
 	}
+	if query.Playlist != "" {
+		wg.Go(func() {
+			playlistData, _ = getSearchPlaylist(ctx, instances, query.Playlist, query.Limit, query.Offset)
+		})
+	}
+	wg.Wait()
 
-	return songData, albumData, artistData, playlistData, nil
+	return songData, albumData, artistData, playlistData
 }
 
-func (p *Hifi) Search(ctx context.Context, userId uint, song, album, artist string) (models.SearchData, error) {
+func searchParams(key string, q string, limit uint, offset uint) string {
+	params := url.Values{}
+	params.Set(key, q)
+	if limit > 0 {
+		params.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	}
+	if offset > 0 {
+		params.Set("offset", strconv.FormatUint(uint64(offset), 10))
+	}
+	return params.Encode()
+}
+
+// page cuts the requested window out of the items when an instance ignored the limit or the offset
+func page[T any](items []T, gotOffset uint, query models.SearchQuery) []T {
+	if gotOffset != query.Offset && gotOffset == 0 {
+		if query.Offset >= uint(len(items)) {
+			return items[:0]
+		}
+		items = items[query.Offset:]
+	}
+	if query.Limit > 0 && uint(len(items)) > query.Limit {
+		items = items[:query.Limit]
+	}
+	return items
+}
+
+func (p *Hifi) Search(ctx context.Context, userId uint, query models.SearchQuery) (models.SearchData, error) {
 	instances, err := repository.ListInstancesByUserIDByAPI(userId, p.Name())
 	if err != nil {
 		return models.SearchData{}, fmt.Errorf("Hifi.Search: %w", err)
@@ -273,17 +288,24 @@ func (p *Hifi) Search(ctx context.Context, userId uint, song, album, artist stri
 		return models.SearchData{}, fmt.Errorf("Hifi.Search: %w", errors.New("not found"))
 	}
 
-	songData, albumData, artistData, playlistData, err := getSearchData(ctx, instances, song, album, artist)
-	if err != nil {
-		return models.SearchData{}, fmt.Errorf("Hifi.Search: %w", err)
-	}
+	songData, albumData, artistData, playlistData := getSearchData(ctx, instances, query)
 
 	result := models.SearchData{
 		Songs:     make([]models.SearchDataSong, 0),
 		Albums:    make([]models.SearchDataAlbum, 0),
 		Artists:   make([]models.SearchDataArtist, 0),
 		Playlists: make([]models.SearchDataPlaylist, 0),
-	}
+		Total: models.SearchDataTotal{
+			Songs:     songData.Data.TotalNumberOfItems,
+			Albums:    albumData.Data.Albums.TotalNumberOfItems,
+			Artists:   artistData.Data.Artists.TotalNumberOfItems,
+			Playlists: playlistData.Data.Playlists.TotalNumberOfItems,
+		},
+	}
+	songData.Data.Songs = page(songData.Data.Songs, songData.Data.Offset, query)
+	albumData.Data.Albums.Albums = page(albumData.Data.Albums.Albums, albumData.Data.Albums.Offset, query)
+	artistData.Data.Artists.Artists = page(artistData.Data.Artists.Artists, artistData.Data.Artists.Offset, query)
+	playlistData.Data.Playlists.Playlists = page(playlistData.Data.Playlists.Playlists, playlistData.Data.Playlists.Offset, query)
 
 	if len(songData.Data.Songs) != 0 {
 		for _, rawSong := range songData.Data.Songs {
@@ -3,10 +3,10 @@ package hifi
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -26,7 +26,7 @@ func fetchSong(ctx context.Context, url2 string, id string) (songData, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return songData{}, fmt.Errorf("fetchAlbum: http: %w", errors.New(resp.Status))
+		return songData{}, fmt.Errorf("fetchAlbum: http: %w", utils.NewStatusError(resp))
 	}
 
 	var data songData
@@ -103,6 +103,19 @@ func getSongData(ctx context.Context, instances []models.Instance, id string) (s
 	return song, downloadInfo, nil
 }
 
+// musicalKey turns the tidal key and scale into the usual notation, FSharp and MINOR giving F#m
+func musicalKey(key string, scale string) string {
+	if key == "" || strings.EqualFold(key, "UNKNOWN") {
+		return ""
+	}
+	key = strings.ReplaceAll(key, "Sharp", "#")
+	key = strings.ReplaceAll(key, "Flat", "b")
+	if strings.EqualFold(scale, "MINOR") {
+		key += "m"
+	}
+	return key
+}
+
 func (p *Hifi) Song(ctx context.Context, userId uint, id string) (models.SongData, error) {
 	instances, err := repository.ListInstancesByUserIDByAPI(userId, p.Name())
 	if err != nil {
@@ -130,6 +143,12 @@ func (p *Hifi) Song(ctx context.Context, userId uint, id string) (models.SongDat
 		Explicit:        data.Data.Explicit,
 		Popularity:      data.Data.Popularity,
 		Isrc:            data.Data.Isrc,
+		Genres:          make([]string, 0),
+		Copyright:       data.Data.Copyright,
+		Composers:       make([]string, 0),
+		Lyricists:       make([]string, 0),
+		Bpm:             data.Data.Bpm,
+		Key:             musicalKey(data.Data.Key, data.Data.KeyScale),
 		Artists:         make([]models.SongDataArtist, 0),
 		Album: models.SongDataAlbum{
 			Id:       strconv.FormatUint(uint64(data.Data.Album.Id), 10),
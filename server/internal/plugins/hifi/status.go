@@ -21,7 +21,7 @@ func (p *Hifi) Status(ctx context.Context, url string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("Hifi.Status: http: %w", errors.New(resp.Status))
+		return fmt.Errorf("Hifi.Status: http: %w", utils.NewStatusError(resp))
 	}
 
 	var status status
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	neturl "net/url"
+	"slices"
 	"strings"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -56,17 +58,45 @@ func (p Hifi) checkSong(arr []string) (models.UrlItem, error) {
 	}, nil
 }
 
-func (p *Hifi) Url(ctx context.Context, userId uint, url string) (models.UrlItem, error) {
-	var clean_url string
-	if url, ok := strings.CutPrefix(url, "https://tidal.com/"); !ok {
-		return models.UrlItem{}, errors.New("Hifi.Url: url not contain \"https://tidal.com/\"")
-	} else {
-		clean_url = url
+var tidalHosts = []string{"tidal.com", "www.tidal.com", "listen.tidal.com"}
+
+// tidalPath returns the path segments of a tidal link, the share variants ("/browse/" prefix,
+// trailing "/u", query string, missing scheme) all come down to the same segments
+func tidalPath(rawUrl string) ([]string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "https://" + rawUrl
+	}
+	parsed, err := neturl.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse: %w", err)
+	}
+	if !slices.Contains(tidalHosts, strings.ToLower(parsed.Hostname())) {
+		return nil, fmt.Errorf("url not on a tidal host: %q", parsed.Hostname())
+	}
+
+	arr := make([]string, 0)
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment != "" {
+			arr = append(arr, segment)
+		}
+	}
+	if len(arr) > 0 && arr[0] == "browse" {
+		arr = arr[1:]
+	}
+	if len(arr) > 0 && arr[len(arr)-1] == "u" {
+		arr = arr[:len(arr)-1]
 	}
+	return arr, nil
+}
 
-	arr := strings.Split(clean_url, "/")
-	if len(arr) == 1 {
-		return models.UrlItem{}, errors.New(fmt.Sprint("Hifi.Url: url contain 1 sub path:", arr))
+func (p *Hifi) Url(ctx context.Context, userId uint, url string) (models.UrlItem, error) {
+	arr, err := tidalPath(url)
+	if err != nil {
+		return models.UrlItem{}, fmt.Errorf("Hifi.Url: %w", err)
+	}
+	if len(arr) < 2 {
+		return models.UrlItem{}, errors.New(fmt.Sprint("Hifi.Url: url contain less than 2 sub path:", arr))
 	}
 	switch arr[0] {
 	case "artist":
@@ -0,0 +1,75 @@
+package hifi
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+func TestTidalPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    []string
+		wantErr bool
+	}{
+		{"plain", "https://tidal.com/track/123", []string{"track", "123"}, false},
+		{"browse prefix", "https://tidal.com/browse/track/123", []string{"track", "123"}, false},
+		{"share suffix", "https://tidal.com/browse/track/123/u", []string{"track", "123"}, false},
+		{"query string", "https://tidal.com/track/123?u", []string{"track", "123"}, false},
+		{"trailing slash", "https://tidal.com/album/456/", []string{"album", "456"}, false},
+		{"listen host", "https://listen.tidal.com/album/456/track/123", []string{"album", "456", "track", "123"}, false},
+		{"www host", "http://www.tidal.com/artist/789", []string{"artist", "789"}, false},
+		{"upper case host", "https://TIDAL.com/playlist/abc-def", []string{"playlist", "abc-def"}, false},
+		{"missing scheme", "tidal.com/browse/playlist/abc-def", []string{"playlist", "abc-def"}, false},
+		{"surrounding spaces", "  https://tidal.com/track/123  ", []string{"track", "123"}, false},
+		{"other host", "https://open.spotify.com/track/123", nil, true},
+		{"lookalike host", "https://nottidal.com/track/123", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tidalPath(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tidalPath(%q) error = %v, wantErr %t", tt.url, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("tidalPath(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHifiUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		wantType models.Type
+		wantId   string
+		wantErr  bool
+	}{
+		{"track", "https://tidal.com/browse/track/123/u", models.TypeSong, "123", false},
+		{"track of an album", "https://listen.tidal.com/album/456/track/123", models.TypeSong, "123", false},
+		{"album", "https://tidal.com/album/456?u", models.TypeAlbum, "456", false},
+		{"artist", "tidal.com/artist/789", models.TypeArtist, "789", false},
+		{"playlist", "https://tidal.com/playlist/abc-def/u", models.TypePlaylist, "abc-def", false},
+		{"no id", "https://tidal.com/track", "", "", true},
+		{"unknown kind", "https://tidal.com/video/123", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Hifi{}
+			got, err := p.Url(context.Background(), 1, tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Url(%q) error = %v, wantErr %t", tt.url, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Type != tt.wantType || got.Id != tt.wantId || got.Provider != "tidal" {
+				t.Errorf("Url(%q) = %+v, want %s %s", tt.url, got, tt.wantType, tt.wantId)
+			}
+		})
+	}
+}
@@ -52,21 +52,23 @@ func GetSong(ctx context.Context, userId uint, provider string, id string) (mode
 		return models.SongData{}, fmt.Errorf("services.GetSong: %w", errors.New("invalid provider name"))
 	}
 
-	var data models.SongData
-	var err error
-	for _, plugin := range plugins {
-		data, err = plugin.Song(ctx, userId, id)
+	return cached(ctx, provider, models.TypeSong, id, func() (models.SongData, error) {
+		var data models.SongData
+		var err error
+		for _, plugin := range plugins {
+			data, err = plugin.Song(ctx, userId, id)
+			if err != nil {
+				continue
+			} else {
+				break
+			}
+		}
 		if err != nil {
-			continue
+			return models.SongData{}, err
 		} else {
-			break
+			return data, nil
 		}
-	}
-	if err != nil {
-		return models.SongData{}, err
-	} else {
-		return data, nil
-	}
+	})
 }
 
 func GetPlaylist(ctx context.Context, userId uint, provider string, id string) (models.PlaylistData, error) {
@@ -75,21 +77,23 @@ func GetPlaylist(ctx context.Context, userId uint, provider string, id string) (
 		return models.PlaylistData{}, fmt.Errorf("services.GetPlaylist: %w", errors.New("invalid provider name"))
 	}
 
-	var data models.PlaylistData
-	var err error
-	for _, plugin := range plugins {
-		data, err = plugin.Playlist(ctx, userId, id)
+	return cached(ctx, provider, models.TypePlaylist, id, func() (models.PlaylistData, error) {
+		var data models.PlaylistData
+		var err error
+		for _, plugin := range plugins {
+			data, err = plugin.Playlist(ctx, userId, id)
+			if err != nil {
+				continue
+			} else {
+				break
+			}
+		}
 		if err != nil {
-			continue
+			return models.PlaylistData{}, err
 		} else {
-			break
+			return data, nil
 		}
-	}
-	if err != nil {
-		return models.PlaylistData{}, err
-	} else {
-		return data, nil
-	}
+	})
 }
 
 func GetAlbum(ctx context.Context, userId uint, provider string, id string) (models.AlbumData, error) {
@@ -98,21 +102,23 @@ func GetAlbum(ctx context.Context, userId uint, provider string, id string) (mod
 		return models.AlbumData{}, fmt.Errorf("services.GetAlbum: %w", errors.New("invalid provider name"))
 	}
 
-	var data models.AlbumData
-	var err error
-	for _, plugin := range plugins {
-		data, err = plugin.Album(ctx, userId, id)
+	return cached(ctx, provider, models.TypeAlbum, id, func() (models.AlbumData, error) {
+		var data models.AlbumData
+		var err error
+		for _, plugin := range plugins {
+			data, err = plugin.Album(ctx, userId, id)
+			if err != nil {
+				continue
+			} else {
+				break
+			}
+		}
 		if err != nil {
-			continue
+			return models.AlbumData{}, err
 		} else {
-			break
+			return data, nil
 		}
-	}
-	if err != nil {
-		return models.AlbumData{}, err
-	} else {
-		return data, nil
-	}
+	})
 }
 
 func GetArtist(ctx context.Context, userId uint, provider string, id string) (models.ArtistData, error) {
@@ -121,34 +127,37 @@ func GetArtist(ctx context.Context, userId uint, provider string, id string) (mo
 		return models.ArtistData{}, fmt.Errorf("services.GetArtist: %w", errors.New("invalid provider name"))
 	}
 
-	var data models.ArtistData
-	var err error
-	for _, plugin := range plugins {
-		data, err = plugin.Artist(ctx, userId, id)
+	return cached(ctx, provider, models.TypeArtist, id, func() (models.ArtistData, error) {
+		var data models.ArtistData
+		var err error
+		for _, plugin := range plugins {
+			data, err = plugin.Artist(ctx, userId, id)
+			if err != nil {
+				continue
+			} else {
+				break
+			}
+		}
 		if err != nil {
-			continue
+			return models.ArtistData{}, err
 		} else {
-			break
+			return data, nil
 		}
-	}
-	if err != nil {
-		return models.ArtistData{}, err
-	} else {
-		return data, nil
-	}
+	})
 }
 
-func Download(ctx context.Context, userId uint, provider string, id string) (io.ReadCloser, string, error) {
+func Download(ctx context.Context, userId uint, provider string, id string, policy models.QualityPolicy) (io.ReadCloser, string, models.QualityLevel, error) {
 	plugins, ok := GetPluginByProvider(provider)
 	if !ok {
-		return nil, "", fmt.Errorf("services.Download: %w", errors.New("invalid provider name"))
+		return nil, "", "", fmt.Errorf("services.Download: %w", errors.New("invalid provider name"))
 	}
 
 	var reader io.ReadCloser
 	var extension string
+	var quality models.QualityLevel
 	var err error
 	for _, plugin := range plugins {
-		reader, extension, err = plugin.Download(ctx, userId, id)
+		reader, extension, quality, err = plugin.Download(ctx, userId, id, policy)
 		if err != nil {
 			continue
 		} else {
@@ -156,8 +165,8 @@ func Download(ctx context.Context, userId uint, provider string, id string) (io.
 		}
 	}
 	if err != nil {
-		return nil, "", err
+		return nil, "", "", err
 	} else {
-		return reader, extension, nil
+		return reader, extension, quality, nil
 	}
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+func AddAutoFetchRun(run *models.AutoFetchRun) error {
+	if err := database.DB.Create(run).Error; err != nil {
+		return fmt.Errorf("repository.AddAutoFetchRun: %w", err)
+	}
+	return nil
+}
+
+func ListAutoFetchRuns(limit int, offset int) ([]models.AutoFetchRun, error) {
+	var runs []models.AutoFetchRun
+	if err := database.DB.
+		Order("started_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListAutoFetchRuns: %w", err)
+	}
+	return runs, nil
+}
+
+func ListAutoFetchRunsByUserID(userId uint, limit int, offset int) ([]models.AutoFetchRun, error) {
+	var runs []models.AutoFetchRun
+	if err := database.DB.
+		Where("user_id = ?", userId).
+		Order("started_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListAutoFetchRunsByUserID: %w", err)
+	}
+	return runs, nil
+}
+
+// PruneAutoFetchRunsByUserID keeps only the most recent runs of the user
+func PruneAutoFetchRunsByUserID(userId uint, keep int) error {
+	if err := database.DB.
+		Where("user_id = ? AND id NOT IN (?)", userId,
+			database.DB.Model(&models.AutoFetchRun{}).
+				Select("id").
+				Where("user_id = ?", userId).
+				Order("started_at DESC").
+				Limit(keep)).
+		Delete(&models.AutoFetchRun{}).Error; err != nil {
+		return fmt.Errorf("repository.PruneAutoFetchRunsByUserID: %w", err)
+	}
+	return nil
+}
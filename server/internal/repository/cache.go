@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+func GetMetadataCache(provider string, kind models.Type, id string) (models.MetadataCache, error) {
+	var entry models.MetadataCache
+	if err := database.DB.
+		Where("provider = ? AND kind = ? AND entity_id = ? AND expires_at > ?", provider, kind, id, time.Now()).
+		First(&entry).Error; err != nil {
+		return models.MetadataCache{}, fmt.Errorf("repository.GetMetadataCache: %w", err)
+	}
+	return entry, nil
+}
+
+func SaveMetadataCache(entry models.MetadataCache) error {
+	if err := database.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entry).Error; err != nil {
+		return fmt.Errorf("repository.SaveMetadataCache: %w", err)
+	}
+	return nil
+}
+
+func CountMetadataCache() (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.MetadataCache{}).Where("expires_at > ?", time.Now()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("repository.CountMetadataCache: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteMetadataCache removes the entries matching the non empty filters, every entry when none is given
+func DeleteMetadataCache(provider string, kind models.Type, id string) (int64, error) {
+	query := database.DB.Where("1 = 1")
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if id != "" {
+		query = query.Where("entity_id = ?", id)
+	}
+	result := query.Delete(&models.MetadataCache{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("repository.DeleteMetadataCache: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func DeleteExpiredMetadataCache() error {
+	if err := database.DB.Where("expires_at <= ?", time.Now()).Delete(&models.MetadataCache{}).Error; err != nil {
+		return fmt.Errorf("repository.DeleteExpiredMetadataCache: %w", err)
+	}
+	return nil
+}
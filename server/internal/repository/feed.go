@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// AddFeedEntry ignores releases already in the feed of the user
+func AddFeedEntry(entry *models.FeedEntry) error {
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error; err != nil {
+		return fmt.Errorf("repository.AddFeedEntry: %w", err)
+	}
+	return nil
+}
+
+func CountFeedEntriesByUserID(userId uint) (int64, error) {
+	var total int64
+	if err := database.DB.Model(&models.FeedEntry{}).
+		Where("user_id = ?", userId).
+		Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("repository.CountFeedEntriesByUserID: %w", err)
+	}
+	return total, nil
+}
+
+func ListFeedEntriesByUserID(userId uint, limit int, offset int) ([]models.FeedEntry, error) {
+	var entries []models.FeedEntry
+	if err := database.DB.
+		Where("user_id = ?", userId).
+		Order("release_date DESC, created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListFeedEntriesByUserID: %w", err)
+	}
+	return entries, nil
+}
+
+func MarkFeedEntryDownloaded(userId uint, provider string, albumId string) error {
+	if err := database.DB.Model(&models.FeedEntry{}).
+		Where("user_id = ? AND provider = ? AND album_id = ?", userId, provider, albumId).
+		Update("downloaded", true).Error; err != nil {
+		return fmt.Errorf("repository.MarkFeedEntryDownloaded: %w", err)
+	}
+	return nil
+}
@@ -2,18 +2,22 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
 )
 
 func AddFollow(userId uint, provider string, artistId string, artistName string, artistPictureUrl string) (*models.Follow, error) {
+	now := time.Now()
 	follow := models.Follow{
 		UserId:           userId,
 		Provider:         provider,
 		ArtistId:         artistId,
 		ArtistName:       artistName,
 		ArtistPictureUrl: artistPictureUrl,
+		LastFetchedAt:    &now,
 	}
 	if err := database.DB.Create(&follow).Error; err != nil {
 		return nil, fmt.Errorf("repository.AddFollow: %w", err)
@@ -45,6 +49,45 @@ func GetFollowByProviderByArtistID(provider string, artistId string) (*models.Fo
 	return &follow, nil
 }
 
+func GetFollowByUserIDByProviderByArtistID(userId uint, provider string, artistId string) (*models.Follow, error) {
+	var follow models.Follow
+	if err := database.DB.First(&follow, "user_id = ? AND provider = ? AND artist_id = ?", userId, provider, artistId).Error; err != nil {
+		return nil, fmt.Errorf("repository.GetFollowByUserIDByProviderByArtistID: %w", err)
+	}
+	return &follow, nil
+}
+
+func GetFollowByUserID(userId uint, id uint) (*models.Follow, error) {
+	var follow models.Follow
+	if err := database.DB.First(&follow, "user_id = ? AND id = ?", userId, id).Error; err != nil {
+		return nil, fmt.Errorf("repository.GetFollowByUserID: %w", err)
+	}
+	return &follow, nil
+}
+
+func UpdateFollowFiltersByUserID(userId uint, id uint, filters models.FollowFilters) error {
+	result := database.DB.Model(&models.Follow{}).
+		Where("user_id = ? AND id = ?", userId, id).
+		Select("release_types", "skip_singles_on_album", "explicit", "include_patterns", "exclude_patterns", "minimum_quality").
+		Updates(models.Follow{Filters: filters})
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateFollowFiltersByUserID: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateFollowFiltersByUserID: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func UpdateFollowLastFetchedAt(id uint, lastFetchedAt time.Time) error {
+	if err := database.DB.Model(&models.Follow{}).
+		Where("id = ?", id).
+		Update("last_fetched_at", lastFetchedAt).Error; err != nil {
+		return fmt.Errorf("repository.UpdateFollowLastFetchedAt: %w", err)
+	}
+	return nil
+}
+
 func DeleteFollow(id uint) error {
 	if err := database.DB.Delete(&models.Follow{}, id).Error; err != nil {
 		return fmt.Errorf("repository.DeleteFollow: %w", err)
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// AddSongHistory numbers the entry after the last revision of its song
+func AddSongHistory(entry *models.SongHistory) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var last uint
+		if err := tx.Model(&models.SongHistory{}).
+			Where("song_id = ?", entry.SongId).
+			Select("COALESCE(MAX(rev), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		entry.Rev = last + 1
+		return tx.Create(entry).Error
+	}); err != nil {
+		return fmt.Errorf("repository.AddSongHistory: %w", err)
+	}
+	return nil
+}
+
+func ListSongHistoryByUserID(userId uint, songId uint) ([]models.SongHistory, error) {
+	history := make([]models.SongHistory, 0)
+	if err := database.DB.
+		Where("user_id = ? AND song_id = ?", userId, songId).
+		Order("rev DESC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListSongHistoryByUserID: %w", err)
+	}
+	return history, nil
+}
+
+// ListSongHistoryFromRevByUserID returns the revision rev and every later one, latest first
+func ListSongHistoryFromRevByUserID(userId uint, songId uint, rev uint) ([]models.SongHistory, error) {
+	history := make([]models.SongHistory, 0)
+	if err := database.DB.
+		Where("user_id = ? AND song_id = ? AND rev >= ?", userId, songId, rev).
+		Order("rev DESC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListSongHistoryFromRevByUserID: %w", err)
+	}
+	return history, nil
+}
+
+func GetLastSongHistory(songId uint) (models.SongHistory, error) {
+	var entry models.SongHistory
+	if err := database.DB.
+		Where("song_id = ?", songId).
+		Order("rev DESC").
+		First(&entry).Error; err != nil {
+		return models.SongHistory{}, fmt.Errorf("repository.GetLastSongHistory: %w", err)
+	}
+	return entry, nil
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"fmt"
+	"strings"
 
 	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
 )
 
 func GetSong(userId uint, id uint) (models.Song, error) {
@@ -49,16 +51,32 @@ func CountSong(q string) (int64, error) {
 	return total, nil
 }
 
-func CountSongByUserID(userId uint, q string) (int64, error) {
+func CountSongByUserID(userId uint, q string, quality models.QualityLevel) (int64, error) {
 	var total int64
-	if err := database.DB.Model(&models.Song{}).
-		Where("path ILIKE ? AND user_id = ?", "%"+q+"%", userId).
+	query := database.DB.Model(&models.Song{}).
+		Where("path ILIKE ? AND user_id = ?", "%"+q+"%", userId)
+	if quality != "" {
+		query = query.Where("audio_quality = ?", quality)
+	}
+	if err := query.
 		Count(&total).Error; err != nil {
 		return 0, fmt.Errorf("repository.CountSong: %w", err)
 	}
 	return total, nil
 }
 
+func CountSongByUserIDByQuality(userId uint) ([]models.ResponseLibraryQuality, error) {
+	var counts []models.ResponseLibraryQuality
+	if err := database.DB.Model(&models.Song{}).
+		Select("audio_quality AS quality, COUNT(*) AS count").
+		Where("user_id = ?", userId).
+		Group("audio_quality").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("repository.CountSongByUserIDByQuality: %w", err)
+	}
+	return counts, nil
+}
+
 func ListSong(q string, limit int, offset int) ([]models.Song, error) {
 	var songs []models.Song
 
@@ -74,11 +92,15 @@ func ListSong(q string, limit int, offset int) ([]models.Song, error) {
 	return songs, nil
 }
 
-func ListSongByUserID(userId uint, q string, limit int, offset int) ([]models.Song, error) {
+func ListSongByUserID(userId uint, q string, quality models.QualityLevel, limit int, offset int) ([]models.Song, error) {
 	var songs []models.Song
 
-	if err := database.DB.
-		Where("path ILIKE ? AND user_id = ?", "%"+q+"%", userId).
+	query := database.DB.
+		Where("path ILIKE ? AND user_id = ?", "%"+q+"%", userId)
+	if quality != "" {
+		query = query.Where("audio_quality = ?", quality)
+	}
+	if err := query.
 		Limit(limit).
 		Offset(offset).
 		Order("m_time DESC NULLS LAST").
@@ -89,8 +111,8 @@ func ListSongByUserID(userId uint, q string, limit int, offset int) ([]models.So
 	return songs, nil
 }
 
-func AddSong(song models.Song) error {
-	if err := database.DB.Create(&song).Error; err != nil {
+func AddSong(song *models.Song) error {
+	if err := database.DB.Create(song).Error; err != nil {
 		return fmt.Errorf("repository.AddSong: %w", err)
 	}
 	return nil
@@ -115,17 +137,132 @@ func UpdateSongByUserID(userId uint, song models.Song) error {
 }
 
 func DeleteSong(id uint) error {
-	if err := database.DB.Delete(&models.Song{}, id).Error; err != nil {
+	if err := database.DB.Unscoped().Delete(&models.Song{}, id).Error; err != nil {
 		return fmt.Errorf("repository.DeleteSong: %w", err)
 	}
 	return nil
 }
 
 func DeleteSongByUserID(userId uint, id uint) error {
-	if err := database.DB.
+	if err := database.DB.Unscoped().
 		Where("id = ? AND user_id = ?", id, userId).
 		Delete(&models.Song{}).Error; err != nil {
 		return fmt.Errorf("repository.DeleteSongByUserID: %w", err)
 	}
 	return nil
 }
+
+// ListOwnedISRCsByUserID returns the subset of isrcs already in the library of the user
+func ListOwnedISRCsByUserID(userId uint, isrcs []string) ([]string, error) {
+	owned := make([]string, 0)
+	if len(isrcs) == 0 {
+		return owned, nil
+	}
+	if err := database.DB.Model(&models.Song{}).
+		Where("user_id = ? AND isrc IN ?", userId, isrcs).
+		Distinct().
+		Pluck("isrc", &owned).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListOwnedISRCsByUserID: %w", err)
+	}
+	return owned, nil
+}
+
+func ListSongsByUserIDByISRCs(userId uint, isrcs []string) ([]models.Song, error) {
+	songs := make([]models.Song, 0)
+	if len(isrcs) == 0 {
+		return songs, nil
+	}
+	if err := database.DB.
+		Where("user_id = ? AND isrc IN ?", userId, isrcs).
+		Order("id").
+		Find(&songs).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListSongsByUserIDByISRCs: %w", err)
+	}
+	return songs, nil
+}
+
+// likeEscaper keeps the wildcards of a search word literal in a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchSongByUserID returns the songs whose path holds every word of the search, no word matches nothing
+func SearchSongByUserID(userId uint, words []string, limit int) ([]models.Song, error) {
+	songs := make([]models.Song, 0)
+	query := database.DB.Where("user_id = ?", userId)
+	matched := false
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		query = query.Where("path ILIKE ?", "%"+likeEscaper.Replace(word)+"%")
+		matched = true
+	}
+	if !matched {
+		return songs, nil
+	}
+	if err := query.
+		Limit(limit).
+		Order("m_time DESC NULLS LAST").
+		Find(&songs).Error; err != nil {
+		return nil, fmt.Errorf("repository.SearchSongByUserID: %w", err)
+	}
+	return songs, nil
+}
+
+func ListSongsByUserIDByIDs(userId uint, ids []uint) ([]models.Song, error) {
+	songs := make([]models.Song, 0)
+	if len(ids) == 0 {
+		return songs, nil
+	}
+	if err := database.DB.
+		Where("user_id = ? AND id IN ?", userId, ids).
+		Find(&songs).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListSongsByUserIDByIDs: %w", err)
+	}
+	return songs, nil
+}
+
+// UpdateSongPathsByUserID moves the songs to their new path in a single transaction, apply runs inside it
+// so the transaction is rolled back when the files could not follow
+func UpdateSongPathsByUserID(userId uint, songs []models.Song, apply func() error) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, song := range songs {
+			if err := tx.Model(&models.Song{}).
+				Where("id = ? AND user_id = ?", song.ID, userId).
+				Updates(models.Song{Path: song.Path, MTime: song.MTime}).Error; err != nil {
+				return err
+			}
+		}
+		return apply()
+	}); err != nil {
+		return fmt.Errorf("repository.UpdateSongPathsByUserID: %w", err)
+	}
+	return nil
+}
+
+func GetSongByUserIDByPath(userId uint, path string) (models.Song, error) {
+	var song models.Song
+	if err := database.DB.
+		First(&song, "user_id = ? AND path = ?", userId, path).Error; err != nil {
+		return models.Song{}, fmt.Errorf("repository.GetSongByUserIDByPath: %w", err)
+	}
+	return song, nil
+}
+
+// ReplaceSongByUserID updates the file fields of a song, move runs last so a failed move rolls the update back
+func ReplaceSongByUserID(userId uint, song models.Song, move func() error) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Song{}).
+			Where("id = ? AND user_id = ?", song.ID, userId).
+			Updates(models.Song{Path: song.Path, AudioQuality: song.AudioQuality, MTime: song.MTime})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return move()
+	}); err != nil {
+		return fmt.Errorf("repository.ReplaceSongByUserID: %w", err)
+	}
+	return nil
+}
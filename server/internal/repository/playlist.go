@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// liveItems hides the items whose song waits in the trash, they come back with the song
+func liveItems(db *gorm.DB) *gorm.DB {
+	return db.Where("playlist_items.song_id IN (SELECT id FROM songs WHERE deleted_at IS NULL)")
+}
+
+func AddPlaylist(playlist *models.Playlist) error {
+	if err := database.DB.Create(playlist).Error; err != nil {
+		return fmt.Errorf("repository.AddPlaylist: %w", err)
+	}
+	return nil
+}
+
+func ListPlaylistsByUserID(userId uint) ([]models.ResponsePlaylist, error) {
+	playlists := make([]models.ResponsePlaylist, 0)
+	if err := database.DB.Model(&models.Playlist{}).
+		Select("playlists.*, (SELECT COUNT(*) FROM playlist_items JOIN songs ON songs.id = playlist_items.song_id WHERE playlist_items.playlist_id = playlists.id AND songs.deleted_at IS NULL) AS count").
+		Where("user_id = ?", userId).
+		Order("name").
+		Scan(&playlists).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListPlaylistsByUserID: %w", err)
+	}
+	return playlists, nil
+}
+
+func GetPlaylistByUserID(userId uint, id uint) (models.Playlist, error) {
+	var playlist models.Playlist
+	if err := database.DB.
+		First(&playlist, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return models.Playlist{}, fmt.Errorf("repository.GetPlaylistByUserID: %w", err)
+	}
+	return playlist, nil
+}
+
+func ListPlaylistItems(playlistId uint) ([]models.PlaylistItem, error) {
+	items := make([]models.PlaylistItem, 0)
+	if err := database.DB.
+		Preload("Song").
+		Scopes(liveItems).
+		Where("playlist_id = ?", playlistId).
+		Order("position, id").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListPlaylistItems: %w", err)
+	}
+	return items, nil
+}
+
+func ListPlaylistsBySongID(songId uint) ([]models.Playlist, error) {
+	playlists := make([]models.Playlist, 0)
+	if err := database.DB.
+		Where("id IN (SELECT playlist_id FROM playlist_items WHERE song_id = ?)", songId).
+		Find(&playlists).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListPlaylistsBySongID: %w", err)
+	}
+	return playlists, nil
+}
+
+func UpdatePlaylistName(id uint, name string, path string) error {
+	if err := database.DB.Model(&models.Playlist{ID: id}).
+		Updates(models.Playlist{Name: name, Path: path}).Error; err != nil {
+		return fmt.Errorf("repository.UpdatePlaylistName: %w", err)
+	}
+	return nil
+}
+
+// AddPlaylistItems appends the songs at the end of the playlist
+func AddPlaylistItems(playlistId uint, songIds []uint) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var last int
+		if err := tx.Model(&models.PlaylistItem{}).
+			Where("playlist_id = ?", playlistId).
+			Select("COALESCE(MAX(position), -1)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+
+		items := make([]models.PlaylistItem, 0, len(songIds))
+		for i, songId := range songIds {
+			items = append(items, models.PlaylistItem{PlaylistId: playlistId, SongId: songId, Position: last + 1 + i})
+		}
+		if len(items) > 0 {
+			if err := tx.Omit("Song").Create(&items).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Playlist{ID: playlistId}).Update("updated_at", time.Now()).Error
+	}); err != nil {
+		return fmt.Errorf("repository.AddPlaylistItems: %w", err)
+	}
+	return nil
+}
+
+// ReorderPlaylistItems gives every item its index in ids, ids must hold every item of the playlist outside
+// the trash
+func ReorderPlaylistItems(playlistId uint, ids []uint) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+		if err := tx.Model(&models.PlaylistItem{}).
+			Scopes(liveItems).
+			Where("playlist_id = ?", playlistId).
+			Pluck("id", &current).Error; err != nil {
+			return err
+		}
+
+		known := make(map[uint]bool, len(current))
+		for _, id := range current {
+			known[id] = true
+		}
+		if len(ids) != len(current) {
+			return errors.New("order must list every item of the playlist")
+		}
+		for _, id := range ids {
+			if !known[id] {
+				return fmt.Errorf("unknown item %d", id)
+			}
+			delete(known, id)
+		}
+
+		for position, id := range ids {
+			if err := tx.Model(&models.PlaylistItem{}).
+				Where("id = ? AND playlist_id = ?", id, playlistId).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Playlist{ID: playlistId}).Update("updated_at", time.Now()).Error
+	}); err != nil {
+		return fmt.Errorf("repository.ReorderPlaylistItems: %w", err)
+	}
+	return nil
+}
+
+func DeletePlaylistItem(playlistId uint, id uint) error {
+	result := database.DB.Delete(&models.PlaylistItem{}, "id = ? AND playlist_id = ?", id, playlistId)
+	if result.Error != nil {
+		return fmt.Errorf("repository.DeletePlaylistItem: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.DeletePlaylistItem: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func DeletePlaylistByUserID(userId uint, id uint) error {
+	if err := database.DB.
+		Delete(&models.Playlist{}, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return fmt.Errorf("repository.DeletePlaylistByUserID: %w", err)
+	}
+	return nil
+}
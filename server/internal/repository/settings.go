@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"fmt"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+func GetSettings() (*models.Settings, error) {
+	var settings models.Settings
+	if err := database.DB.First(&settings, "id = ?", true).Error; err != nil {
+		return nil, fmt.Errorf("repository.GetSettings: %w", err)
+	}
+	return &settings, nil
+}
+
+func UpdateSettings(settings *models.Settings) error {
+	if err := database.DB.Model(&models.Settings{}).Where("id = ?", true).Select("*").Omit("id").Updates(settings).Error; err != nil {
+		return fmt.Errorf("repository.UpdateSettings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
+)
+
+func AddSubscription(subscription *models.PlaylistSubscription) error {
+	if err := database.DB.Create(subscription).Error; err != nil {
+		return fmt.Errorf("repository.AddSubscription: %w", err)
+	}
+	return nil
+}
+
+func ListSubscriptionsByUserID(userId uint) ([]models.PlaylistSubscription, error) {
+	var subscriptions []models.PlaylistSubscription
+	if err := database.DB.
+		Where("user_id = ?", userId).
+		Order("title").
+		Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListSubscriptionsByUserID: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func GetSubscriptionByUserID(userId uint, id uint) (models.PlaylistSubscription, error) {
+	var subscription models.PlaylistSubscription
+	if err := database.DB.
+		First(&subscription, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return models.PlaylistSubscription{}, fmt.Errorf("repository.GetSubscriptionByUserID: %w", err)
+	}
+	return subscription, nil
+}
+
+func UpdateSubscriptionDropRemovedByUserID(userId uint, id uint, dropRemoved bool) error {
+	result := database.DB.Model(&models.PlaylistSubscription{}).
+		Where("id = ? AND user_id = ?", id, userId).
+		Update("drop_removed", dropRemoved)
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateSubscriptionDropRemovedByUserID: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateSubscriptionDropRemovedByUserID: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func UpdateSubscriptionSongs(id uint, title string, coverUrl string, songs []models.SubscriptionSong, syncedAt time.Time) error {
+	if err := database.DB.Model(&models.PlaylistSubscription{ID: id}).
+		Select("title", "cover_url", "songs", "last_synced_at").
+		Updates(models.PlaylistSubscription{Title: title, CoverUrl: coverUrl, Songs: songs, LastSyncedAt: &syncedAt}).Error; err != nil {
+		return fmt.Errorf("repository.UpdateSubscriptionSongs: %w", err)
+	}
+	return nil
+}
+
+// UpdateSubscriptionLinks saves the library songs linked to the playlist songs between two syncs
+func UpdateSubscriptionLinks(id uint, songs []models.SubscriptionSong) error {
+	if err := database.DB.Model(&models.PlaylistSubscription{ID: id}).
+		Select("songs").
+		Updates(models.PlaylistSubscription{Songs: songs}).Error; err != nil {
+		return fmt.Errorf("repository.UpdateSubscriptionLinks: %w", err)
+	}
+	return nil
+}
+
+func DeleteSubscriptionByUserID(userId uint, id uint) error {
+	if err := database.DB.
+		Delete(&models.PlaylistSubscription{}, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return fmt.Errorf("repository.DeleteSubscriptionByUserID: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// trashedSong loads the song of a trash item, which is hidden from every other query
+func trashedSong(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// TrashSongByUserID soft deletes the song and records its trash item, move runs last so a failed move
+// rolls both back
+func TrashSongByUserID(userId uint, item *models.TrashItem, move func() error) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", item.SongId, userId).Delete(&models.Song{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return move()
+	}); err != nil {
+		return fmt.Errorf("repository.TrashSongByUserID: %w", err)
+	}
+	return nil
+}
+
+// RestoreTrashItemByUserID brings the song of a trash item back with its id, move runs last so a failed
+// move rolls both back
+func RestoreTrashItemByUserID(userId uint, item models.TrashItem, move func() error) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", item.ID, userId).Delete(&models.TrashItem{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Unscoped().Model(&models.Song{}).
+			Where("id = ? AND user_id = ?", item.SongId, userId).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return move()
+	}); err != nil {
+		return fmt.Errorf("repository.RestoreTrashItemByUserID: %w", err)
+	}
+	return nil
+}
+
+func GetTrashItemByUserID(userId uint, id uint) (models.TrashItem, error) {
+	var item models.TrashItem
+	if err := database.DB.
+		Preload("Song", trashedSong).
+		First(&item, "id = ? AND user_id = ?", id, userId).Error; err != nil {
+		return models.TrashItem{}, fmt.Errorf("repository.GetTrashItemByUserID: %w", err)
+	}
+	return item, nil
+}
+
+func ListTrashItemsByUserID(userId uint) ([]models.TrashItem, error) {
+	items := make([]models.TrashItem, 0)
+	if err := database.DB.
+		Preload("Song", trashedSong).
+		Where("user_id = ?", userId).
+		Order("deleted_at DESC").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListTrashItemsByUserID: %w", err)
+	}
+	return items, nil
+}
+
+func ListTrashItemsDeletedBefore(date time.Time) ([]models.TrashItem, error) {
+	items := make([]models.TrashItem, 0)
+	if err := database.DB.
+		Preload("Song", trashedSong).
+		Where("deleted_at < ?", date).
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListTrashItemsDeletedBefore: %w", err)
+	}
+	return items, nil
+}
+
+// PurgeSong deletes a song for good, its trash item, history and playlist entries go with it
+func PurgeSong(id uint) error {
+	if err := database.DB.Unscoped().Delete(&models.Song{}, id).Error; err != nil {
+		return fmt.Errorf("repository.PurgeSong: %w", err)
+	}
+	return nil
+}
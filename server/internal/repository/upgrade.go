@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"fmt"
+
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func ListUpgradeCandidatesByUserID(userId uint) ([]models.UpgradeCandidate, error) {
+	var candidates []models.UpgradeCandidate
+
+	if err := database.DB.
+		Where("user_id = ?", userId).
+		Order("path ASC").
+		Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListUpgradeCandidatesByUserID: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func ListUpgradeCandidatesByUserIDByIDs(userId uint, ids []uint) ([]models.UpgradeCandidate, error) {
+	var candidates []models.UpgradeCandidate
+
+	if err := database.DB.
+		Where("user_id = ? AND id IN ?", userId, ids).
+		Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("repository.ListUpgradeCandidatesByUserIDByIDs: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func ReplaceUpgradeCandidatesByUserID(userId uint, candidates []models.UpgradeCandidate) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.UpgradeCandidate{}).Error; err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(&candidates).Error
+	}); err != nil {
+		return fmt.Errorf("repository.ReplaceUpgradeCandidatesByUserID: %w", err)
+	}
+	return nil
+}
+
+func DeleteUpgradeCandidateBySongID(userId uint, songId uint) error {
+	if err := database.DB.
+		Where("user_id = ? AND song_id = ?", userId, songId).
+		Delete(&models.UpgradeCandidate{}).Error; err != nil {
+		return fmt.Errorf("repository.DeleteUpgradeCandidateBySongID: %w", err)
+	}
+	return nil
+}
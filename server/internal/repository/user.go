@@ -53,9 +53,68 @@ func UpdateUser(id uint, updates *models.RequestUser) error {
 	return nil
 }
 
+func UpdateUserQualityPolicy(id uint, policy models.QualityPolicy) error {
+	result := database.DB.Model(&models.User{}).Where("id = ?", id).
+		Select("quality_preferences", "quality_minimum", "quality_fallback").
+		Updates(models.User{
+			QualityPreferences: policy.Preferences,
+			QualityMinimum:     policy.Minimum,
+			QualityFallback:    policy.Fallback,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateUserQualityPolicy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateUserQualityPolicy: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func UpdateUserAutoFetchSchedule(id uint, schedule string) error {
+	result := database.DB.Model(&models.User{}).Where("id = ?", id).Update("auto_fetch_schedule", schedule)
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateUserAutoFetchSchedule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateUserAutoFetchSchedule: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func GetUserByFeedToken(token string) (*models.User, error) {
+	var user models.User
+	err := database.DB.Take(&user, "feed_token = ?", token).Error
+	if err != nil {
+		return nil, fmt.Errorf("repository.GetUserByFeedToken: %w", err)
+	}
+	return &user, nil
+}
+
+func UpdateUserFeedToken(id uint, token string) error {
+	result := database.DB.Model(&models.User{}).Where("id = ?", id).Update("feed_token", token)
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateUserFeedToken: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateUserFeedToken: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
 func DeleteUser(id uint) error {
 	if err := database.DB.Delete(&models.User{}, id).Error; err != nil {
 		return fmt.Errorf("repository.CreateUser: %w", err)
 	}
 	return nil
 }
+
+func UpdateUserTagProfile(id uint, profile models.TagProfile) error {
+	result := database.DB.Model(&models.User{}).Where("id = ?", id).Update("tag_profile", profile)
+	if result.Error != nil {
+		return fmt.Errorf("repository.UpdateUserTagProfile: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("repository.UpdateUserTagProfile: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
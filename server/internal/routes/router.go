@@ -41,6 +41,14 @@ func SetupRouters() *graceful.Graceful {
 			me.Use(middlewares.Logged())
 			me.GET("", handlers.Me)
 			me.PUT("", handlers.MeUpdate)
+			me.GET("/quality", handlers.MeQuality)
+			me.PUT("/quality", handlers.MeUpdateQuality)
+			me.GET("/autofetch", handlers.MeAutoFetch)
+			me.PUT("/autofetch", handlers.MeUpdateAutoFetch)
+			me.GET("/tagging", handlers.MeTagProfile)
+			me.PUT("/tagging", handlers.MeUpdateTagProfile)
+			me.GET("/feed", handlers.MeFeedToken)
+			me.POST("/feed", handlers.MeRenewFeedToken)
 		}
 
 		api.POST("/login", middlewares.RateLimiter("5-M"), middlewares.LoggedOut(), handlers.Login)
@@ -51,6 +59,7 @@ func SetupRouters() *graceful.Graceful {
 		api.GET("/artist/:provider/:id", middlewares.Logged(), handlers.GetArtist)
 		api.GET("/playlist/:provider/:id", middlewares.Logged(), handlers.GetPlaylist)
 		api.GET("/search", middlewares.Logged(), handlers.Search)
+		api.GET("/search/unified", middlewares.Logged(), handlers.UnifiedSearch)
 
 		admin := api.Group("/admin")
 		{
@@ -58,6 +67,12 @@ func SetupRouters() *graceful.Graceful {
 			admin.POST("/login", middlewares.RateLimiter("5-M"), middlewares.Admout(), handlers.AdminLogin)
 			admin.PUT("/password", middlewares.Admin(), handlers.AdminPassword)
 			admin.POST("/logout", middlewares.Admin(), handlers.AdminLogout)
+			admin.GET("/settings", middlewares.Admin(), handlers.AdminSettings)
+			admin.PUT("/settings", middlewares.Admin(), handlers.AdminUpdateSettings)
+			admin.GET("/autofetch/runs", middlewares.Admin(), handlers.AdminListAutoFetchRuns)
+			admin.POST("/autofetch/run", middlewares.Admin(), handlers.AdminRunAutoFetch)
+			admin.GET("/cache", middlewares.Admin(), handlers.AdminCacheStats)
+			admin.DELETE("/cache", middlewares.Admin(), handlers.AdminInvalidateCache)
 		}
 
 		users := api.Group("/users")
@@ -82,12 +97,19 @@ func SetupRouters() *graceful.Graceful {
 		{
 			downloads.Use(middlewares.Logged())
 			downloads.POST("", handlers.AddDownload)
+			downloads.POST("/urls", handlers.AddDownloadUrls)
 			downloads.GET("", handlers.ListDownload)
 			downloads.DELETE("/:id", handlers.DeleteDownload)
 			downloads.POST("/:id/retry", handlers.RetryDownload)
 			downloads.POST("/:id/cancel", handlers.CancelDownload)
 			downloads.POST("/retry", handlers.RetryDownloads)
 			downloads.POST("/done", handlers.DoneDownloads)
+			downloads.POST("/import", handlers.ImportExternalPlaylist)
+			downloads.POST("/import/confirm", handlers.ConfirmExternalPlaylist)
+			downloads.GET("/jobs", handlers.ListDownloadJobs)
+			downloads.DELETE("/jobs/:id", handlers.DeleteDownloadJob)
+			downloads.POST("/jobs/:id/retry", handlers.RetryDownloadJob)
+			downloads.POST("/jobs/:id/cancel", handlers.CancelDownloadJob)
 		}
 
 		follows := api.Group("/follows")
@@ -95,16 +117,70 @@ func SetupRouters() *graceful.Graceful {
 			follows.Use(middlewares.Logged())
 			follows.POST("", handlers.AddFollow)
 			follows.GET("", handlers.ListFollows)
+			follows.GET("/runs", handlers.ListAutoFetchRuns)
+			follows.GET("/releases", handlers.PreviewReleases)
+			follows.POST("/fetch", handlers.FetchReleases)
+			follows.POST("/import/library", handlers.ImportFollowsFromLibrary)
+			follows.POST("/import/playlist", handlers.ImportFollowsFromPlaylist)
+			follows.POST("/import/confirm", handlers.ConfirmFollowsImport)
+			follows.GET("/missing", handlers.ListMissing)
+			follows.POST("/missing", handlers.QueueMissing)
 			follows.DELETE("/:id", handlers.DeleteFollow)
+			follows.GET("/:id/filters", handlers.GetFollowFilters)
+			follows.PUT("/:id/filters", handlers.UpdateFollowFilters)
+			follows.GET("/:id/missing", handlers.ListMissing)
+			follows.POST("/:id/missing", handlers.QueueMissing)
+		}
+
+		playlists := api.Group("/playlists")
+		{
+			playlists.Use(middlewares.Logged())
+			playlists.POST("", handlers.CreateLibraryPlaylist)
+			playlists.GET("", handlers.ListLibraryPlaylists)
+			playlists.POST("/import", handlers.ImportLibraryPlaylist)
+			playlists.GET("/:id", handlers.GetLibraryPlaylist)
+			playlists.PUT("/:id", handlers.UpdateLibraryPlaylist)
+			playlists.DELETE("/:id", handlers.DeleteLibraryPlaylist)
+			playlists.GET("/:id/export", handlers.ExportLibraryPlaylist)
+			playlists.POST("/:id/songs", handlers.AddLibraryPlaylistSongs)
+			playlists.PUT("/:id/songs", handlers.ReorderLibraryPlaylist)
+			playlists.DELETE("/:id/songs/:itemId", handlers.DeleteLibraryPlaylistItem)
+		}
+
+		subscriptions := api.Group("/subscriptions")
+		{
+			subscriptions.Use(middlewares.Logged())
+			subscriptions.POST("", handlers.AddSubscription)
+			subscriptions.GET("", handlers.ListSubscriptions)
+			subscriptions.PUT("/:id", handlers.UpdateSubscription)
+			subscriptions.DELETE("/:id", handlers.DeleteSubscription)
+			subscriptions.POST("/:id/sync", handlers.SyncSubscription)
+		}
+
+		feed := api.Group("/feed")
+		{
+			feed.GET("", middlewares.Logged(), handlers.ListFeed)
+			feed.GET("/atom", middlewares.FeedToken(), handlers.FeedAtom)
 		}
 
 		library := api.Group("/library")
 		{
 			library.Use(middlewares.Logged())
 			library.GET("", handlers.ListSong)
+			library.GET("/quality", handlers.ListSongQuality)
+			library.GET("/upgrades", handlers.ListUpgrades)
+			library.POST("/upgrades", handlers.QueueUpgrades)
+			library.POST("/upgrades/scan", handlers.ScanUpgrades)
 			library.POST("", handlers.UploadSong)
+			library.PUT("/bulk", handlers.BulkEditSongs)
+			library.GET("/trash", handlers.ListTrash)
+			library.DELETE("/trash", handlers.EmptyTrash)
+			library.POST("/trash/:id/restore", handlers.RestoreTrashItem)
+			library.DELETE("/trash/:id", handlers.PurgeTrashItem)
 			library.PUT("/:id", handlers.EditSong)
 			library.GET("/:id/img", handlers.GetSongCover)
+			library.GET("/:id/history", handlers.ListSongHistory)
+			library.POST("/:id/history/:rev/revert", handlers.RevertSongHistory)
 			library.DELETE("/:id", handlers.DeleteSong)
 			library.PUT("", handlers.SyncLibrary)
 		}
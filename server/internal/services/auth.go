@@ -10,6 +10,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// ValidateUsername keeps the username a plain folder name, the dot-prefixed folders next to the user
+// folders (.trash, .thumbnails) can never be taken
 func ValidateUsername(username string) error {
 	regexUsername := regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
 	if !regexUsername.MatchString(username) {
@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -20,42 +21,72 @@ import (
 )
 
 type downloadManager struct {
-	mTasks      sync.Mutex
-	tasks       map[uint]map[uint]*downloadTask
-	mIds        sync.Mutex
-	ids         map[uint]uint
-	limitGlobal chan struct{}
+	mTasks    sync.Mutex
+	tasks     map[uint]map[uint]*downloadTask
+	jobs      map[uint]map[uint]*downloadJob
+	mIds      sync.Mutex
+	ids       map[uint]uint
+	jobIds    map[uint]uint
+	scheduler *downloadScheduler
+	retry     *downloadRetryPolicy
+}
+
+// downloadOptions are shared by the tasks of a job
+type downloadOptions struct {
+	replace *models.Song
+	minimum models.QualityLevel
 }
 
 type downloadTask struct {
 	mu             sync.Mutex
 	userId         uint
+	jobId          uint
+	priority       models.Priority
 	provider       string
 	songId         string
 	songData       models.SongData
+	options        downloadOptions
 	status         models.Status
+	err            string
+	errClass       models.ErrorClass
+	attempts       uint
+	retryAt        *time.Time
+	retryTimer     *time.Timer
+	downloadCtx    context.Context
 	downloadCancel context.CancelFunc
 }
 
 var DownloadManager = downloadManager{
-	mTasks:      sync.Mutex{},
-	tasks:       make(map[uint]map[uint]*downloadTask),
-	mIds:        sync.Mutex{},
-	ids:         make(map[uint]uint),
-	limitGlobal: make(chan struct{}, 3),
+	mTasks: sync.Mutex{},
+	tasks:  make(map[uint]map[uint]*downloadTask),
+	jobs:   make(map[uint]map[uint]*downloadJob),
+	mIds:   sync.Mutex{},
+	ids:    make(map[uint]uint),
+	jobIds: make(map[uint]uint),
+	retry:  newDownloadRetryPolicy(),
+}
+
+func init() {
+	// a task reports back to the manager when it ends, so the scheduler is wired once the manager exists
+	DownloadManager.scheduler = newDownloadScheduler(func(t *downloadTask) {
+		go t.run()
+	})
 }
 
-func (m *downloadManager) acquireLimitGlobal(ctx context.Context) error {
-	select {
-	case m.limitGlobal <- struct{}{}:
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+// LoadSettings applies the saved settings to the download manager and the provider cache, it runs once
+// the database is connected
+func LoadSettings() {
+	if settings, err := repository.GetSettings(); err != nil {
+		log.Println("services.LoadSettings:", err)
+	} else {
+		DownloadManager.ApplySettings(settings)
+		plugins.Cache.ApplySettings(settings)
 	}
 }
 
-func (m *downloadManager) releaseLimitGlobal() {
-	<-m.limitGlobal
+func (m *downloadManager) ApplySettings(settings *models.Settings) {
+	m.scheduler.setLimits(settings.DownloadGlobalLimit, settings.DownloadUserLimit)
+	m.retry.set(settings.DownloadRetryLimit, time.Duration(settings.DownloadRetryDelay)*time.Second)
 }
 
 func (m *downloadManager) generateId(userId uint) uint {
@@ -71,45 +102,40 @@ func (m *downloadManager) generateId(userId uint) uint {
 	return id
 }
 
-func (m *downloadManager) AddArtist(userId uint, provider string, artistId string) {
-	artist, err := plugins.GetArtist(context.Background(), userId, provider, artistId)
-	if err != nil {
-		log.Println("downloadManager.AddArtist: ", err)
-		return
-	}
-	for _, album := range artist.Albums {
-		m.AddAlbum(userId, provider, album.Id)
-	}
+func (m *downloadManager) AddArtist(userId uint, provider string, artistId string, priority models.Priority) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypeArtist), Id: artistId}, priority, downloadOptions{})
 }
 
-func (m *downloadManager) AddAlbum(userId uint, provider string, albumId string) {
-	album, err := plugins.GetAlbum(context.Background(), userId, provider, albumId)
-	if err != nil {
-		log.Println("downloadManager.AddAlbum: ", err)
-		return
-	}
-	for _, song := range album.Songs {
-		m.AddSong(userId, provider, song.Id)
-	}
+func (m *downloadManager) AddAlbum(userId uint, provider string, albumId string, priority models.Priority) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypeAlbum), Id: albumId}, priority, downloadOptions{})
 }
 
-func (m *downloadManager) AddPlaylist(userId uint, provider string, albumId string) {
-	playlist, err := plugins.GetPlaylist(context.Background(), userId, provider, albumId)
-	if err != nil {
-		log.Println("downloadManager.AddPlaylist: ", err)
-		return
-	}
-	for _, song := range playlist.Songs {
-		m.AddSong(userId, provider, song.Id)
-	}
+func (m *downloadManager) AddPlaylist(userId uint, provider string, playlistId string, priority models.Priority) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypePlaylist), Id: playlistId}, priority, downloadOptions{})
+}
+
+func (m *downloadManager) AddSong(userId uint, provider string, songId string, priority models.Priority) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypeSong), Id: songId}, priority, downloadOptions{})
+}
+
+// AddAutoFetchAlbum queues a followed release, songs below the minimum quality of the follow are refused
+func (m *downloadManager) AddAutoFetchAlbum(userId uint, provider string, albumId string, minimum models.QualityLevel) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypeAlbum), Id: albumId}, models.PriorityAutoFetch, downloadOptions{minimum: minimum})
+}
+
+// AddUpgrade queues the download of a better version of a library song, the file is replaced in place
+func (m *downloadManager) AddUpgrade(userId uint, provider string, songId string, song models.Song) {
+	m.addJob(userId, models.RequestDownload{Provider: provider, Type: string(models.TypeSong), Id: songId}, models.PriorityAutoFetch, downloadOptions{replace: &song})
 }
 
-func (m *downloadManager) AddSong(userId uint, provider string, songId string) {
+func (m *downloadManager) addTask(userId uint, jobId uint, provider string, songId string, priority models.Priority) uint {
 	taskId := m.generateId(userId)
 
 	newTask := &downloadTask{
 		mu:             sync.Mutex{},
 		userId:         userId,
+		jobId:          jobId,
+		priority:       priority,
 		provider:       provider,
 		songId:         songId,
 		songData:       models.SongData{},
@@ -124,32 +150,40 @@ func (m *downloadManager) AddSong(userId uint, provider string, songId string) {
 		m.tasks[userId] = userTasks
 	}
 	userTasks[taskId] = newTask
+	if job, ok := m.jobs[userId][jobId]; ok {
+		job.mu.Lock()
+		job.taskIds = append(job.taskIds, taskId)
+		newTask.options = job.options
+		job.mu.Unlock()
+	}
 	m.mTasks.Unlock()
 
-	go newTask.start()
+	newTask.start()
+
+	return taskId
 }
 
-func saveSong(ctx context.Context, userId uint, reader io.ReadCloser, extension string, data models.SongData) error {
+func saveSong(ctx context.Context, userId uint, reader io.ReadCloser, extension string, quality models.QualityLevel, data models.SongData) (models.Song, error) {
 	defer reader.Close()
 
 	user, err := repository.GetUserByID(userId)
 	if err != nil {
-		return fmt.Errorf("saveSong: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: %w", err)
 	}
 
 	root, err := os.OpenRoot(config.LIBRARY_PATH)
 	if err != nil {
-		return fmt.Errorf("saveSong: os.OpenRoot: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: os.OpenRoot: %w", err)
 	}
 	defer root.Close()
 
 	if err := root.Mkdir(user.Username, 0755); err != nil && !os.IsExist(err) {
-		return fmt.Errorf("saveSong: root.Mkdir: 1: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: root.Mkdir: 1: %w", err)
 	}
 
 	rootUser, err := root.OpenRoot(user.Username)
 	if err != nil {
-		return fmt.Errorf("saveSong: root.OpenRoot: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: root.OpenRoot: %w", err)
 	}
 	defer rootUser.Close()
 
@@ -161,39 +195,40 @@ func saveSong(ctx context.Context, userId uint, reader io.ReadCloser, extension
 	dirFile := filepath.Dir(filename)
 
 	if err := rootUser.MkdirAll(dirFile, 0755); err != nil {
-		return fmt.Errorf("saveSong: rootUser.MkdirAll: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: rootUser.MkdirAll: %w", err)
 	}
 
 	file, err := rootUser.Create(filename)
 	if err != nil {
-		return fmt.Errorf("saveSong: root.Create: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: root.Create: %w", err)
 	}
 
 	if _, err := io.Copy(file, reader); err != nil {
 		_ = file.Close()
 		if removeErr := rootUser.Remove(filename); removeErr != nil {
-			return fmt.Errorf("saveSong: io.Copy: %w: %w", err, removeErr)
+			return models.Song{}, fmt.Errorf("saveSong: io.Copy: %w: %w", err, removeErr)
 		} else {
-			return fmt.Errorf("saveSong: io.Copy: %w", err)
+			return models.Song{}, fmt.Errorf("saveSong: io.Copy: %w", err)
 		}
 	}
 	if err := file.Close(); err != nil {
-		return fmt.Errorf("saveSong: file.Close: %w", err)
+		return models.Song{}, fmt.Errorf("saveSong: file.Close: %w", err)
 	}
 
 	path := filepath.Join(rootUser.Name(), filename)
 	if err := metadata.FormatMetadata(ctx, userId, path, data); err != nil {
 		_ = file.Close()
 		if removeErr := rootUser.Remove(filename); removeErr != nil {
-			return fmt.Errorf("saveSong: %w: %w", err, removeErr)
+			return models.Song{}, fmt.Errorf("saveSong: %w: %w: %w", models.ErrTagging, err, removeErr)
 		} else {
-			return fmt.Errorf("saveSong: %w", err)
+			return models.Song{}, fmt.Errorf("saveSong: %w: %w", models.ErrTagging, err)
 		}
 	}
 
-	_ = repository.AddSong(models.Song{UserId: userId, Path: filename, Isrc: data.Isrc, MTime: time.Now()})
+	song := models.Song{UserId: userId, Path: filename, Isrc: data.Isrc, AudioQuality: quality, MTime: time.Now()}
+	_ = repository.AddSong(&song)
 
-	return nil
+	return song, nil
 }
 
 func (t *downloadTask) start() {
@@ -203,93 +238,172 @@ func (t *downloadTask) start() {
 		return
 	}
 
+	if t.downloadCancel != nil {
+		t.downloadCancel()
+	}
 	ctx, cancel := context.WithCancel(context.Background())
+	t.downloadCtx = ctx
 	t.downloadCancel = cancel
+	t.status = models.StatusPending
+	t.retryAt = nil
+	t.retryTimer = nil
 	t.mu.Unlock()
 
-	go t.run(ctx)
+	go t.prepare(ctx)
 }
 
-func (t *downloadTask) run(ctx context.Context) {
+func (t *downloadTask) prepare(ctx context.Context) {
 	song, err := plugins.GetSong(ctx, t.userId, t.provider, t.songId)
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			t.mu.Lock()
-			t.status = models.StatusCancel
-			t.mu.Unlock()
-		} else {
-			t.mu.Lock()
-			t.status = models.StatusFailed
-			t.mu.Unlock()
-		}
-		log.Println("downloadTask.run: ", err)
+		t.fail(ctx, fmt.Errorf("downloadTask.prepare: %w", err))
 		return
-	} else {
-		t.mu.Lock()
-		t.songData = song
+	}
+
+	t.mu.Lock()
+	if t.downloadCtx != ctx || t.status != models.StatusPending {
 		t.mu.Unlock()
+		return
 	}
+	t.songData = song
+	t.mu.Unlock()
 
 	// check if the user already own this song
 	// if false {
 	//    return
 	// }
 
-	if err := DownloadManager.acquireLimitGlobal(ctx); err != nil {
-		t.mu.Lock()
-		t.status = models.StatusCancel
+	DownloadManager.scheduler.enqueue(t)
+}
+
+func (t *downloadTask) run() {
+	defer DownloadManager.scheduler.release(t)
+
+	t.mu.Lock()
+	ctx, cancel := t.downloadCtx, t.downloadCancel
+	if ctx.Err() != nil || t.status != models.StatusPending {
 		t.mu.Unlock()
 		return
-	} else {
-		t.mu.Lock()
-		t.status = models.StatusRunning
-		t.mu.Unlock()
 	}
-	defer DownloadManager.releaseLimitGlobal()
+	t.status = models.StatusRunning
+	t.mu.Unlock()
+
+	user, err := repository.GetUserByID(t.userId)
+	if err != nil {
+		t.fail(ctx, fmt.Errorf("downloadTask.run: %w", err))
+		return
+	}
+
+	policy := user.QualityPolicy()
+	if t.options.replace != nil {
+		policy = policy.Above(t.options.replace.AudioQuality)
+	}
+	if t.options.minimum.Rank() > policy.Minimum.Rank() {
+		policy.Minimum = t.options.minimum
+	}
 
-	reader, extension, err := plugins.Download(ctx, t.userId, t.provider, t.songId)
+	reader, extension, quality, err := plugins.Download(ctx, t.userId, t.provider, t.songId, policy)
 
 	if err == nil {
-		err = saveSong(ctx, t.userId, reader, extension, t.songData)
+		if t.options.replace != nil {
+			err = replaceSong(ctx, t.userId, reader, extension, quality, t.songData, *t.options.replace)
+		} else {
+			var song models.Song
+			song, err = saveSong(ctx, t.userId, reader, extension, quality, t.songData)
+			if err == nil && song.ID != 0 {
+				linkSubscriptionSong(t.userId, t.provider, t.songId, song)
+			}
+		}
 	}
 
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			t.mu.Lock()
-			if t.status != models.StatusFailed {
-				t.status = models.StatusCancel
-			}
-			t.mu.Unlock()
-		} else {
-			t.downloadCancel()
-			t.mu.Lock()
-			t.status = models.StatusFailed
-			t.mu.Unlock()
+		if !errors.Is(err, context.Canceled) {
+			cancel()
 		}
-		log.Println("downloadTask.run: ", err)
-	} else {
-		t.mu.Lock()
+		t.fail(ctx, fmt.Errorf("downloadTask.run: %w", err))
+		return
+	}
+
+	t.mu.Lock()
+	// a cancel landing while the file was saved already set the task to cancelled
+	done := t.downloadCtx == ctx && t.status == models.StatusRunning
+	if done {
 		t.status = models.StatusDone
-		t.mu.Unlock()
+		t.err = ""
+		t.errClass = ""
+	}
+	t.mu.Unlock()
+
+	if done {
+		DownloadManager.jobTaskDone(t.userId, t.jobId)
 	}
 }
 
+// fail records the classified error of an attempt and schedules an automatic retry for transient errors,
+// the result of an attempt that was replaced by a retry in the meantime is ignored
+func (t *downloadTask) fail(ctx context.Context, err error) {
+	log.Println(err)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.downloadCtx != ctx {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		if t.status != models.StatusFailed {
+			t.status = models.StatusCancel
+		}
+		return
+	}
+
+	t.err = err.Error()
+	t.errClass = classifyDownloadError(err)
+
+	delay, ok := DownloadManager.retry.backoff(t.attempts, t.errClass)
+	if !ok {
+		t.status = models.StatusFailed
+		return
+	}
+
+	t.attempts++
+	t.status = models.StatusPending
+	retryAt := time.Now().Add(delay)
+	t.retryAt = &retryAt
+	t.retryTimer = time.AfterFunc(delay, func() {
+		t.mu.Lock()
+		waiting := t.downloadCtx == ctx && t.status == models.StatusPending
+		t.mu.Unlock()
+		if waiting {
+			t.start()
+		}
+	})
+}
+
 func (t *downloadTask) cancel() {
 	t.mu.Lock()
-	if t.status == models.StatusRunning {
+	if t.status == models.StatusRunning || t.status == models.StatusPending {
 		t.downloadCancel()
+		if t.retryTimer != nil {
+			t.retryTimer.Stop()
+		}
 		t.status = models.StatusCancel
 	}
 	t.mu.Unlock()
+
+	DownloadManager.scheduler.remove(t)
 }
 
 func (t *downloadTask) retry() {
 	t.mu.Lock()
-	if t.status != models.StatusCancel && t.status != models.StatusFailed {
+	waiting := t.retryTimer != nil && t.status == models.StatusPending
+	if t.status != models.StatusCancel && t.status != models.StatusFailed && !waiting {
 		t.mu.Unlock()
 		return
 	}
-	t.status = models.StatusPending
+	if waiting {
+		t.retryTimer.Stop()
+	}
+	t.attempts = 0
 	t.mu.Unlock()
 
 	t.start()
@@ -336,7 +450,7 @@ func (m *downloadManager) Done(userId uint) {
 		task.mu.Unlock()
 	}
 	for _, id := range doneList {
-		delete(m.tasks[userId], id)
+		m.deleteTask(userId, id)
 	}
 	m.mTasks.Unlock()
 }
@@ -349,24 +463,69 @@ func (m *downloadManager) Remove(userId uint, taskId uint) error {
 		return errors.New("download not found")
 	}
 	task.cancel()
-	delete(m.tasks[userId], taskId)
+	m.deleteTask(userId, taskId)
 	m.mTasks.Unlock()
 	return nil
 }
 
-func (m *downloadManager) List(userId uint) []models.DownloadData {
+// deleteTask must be called with mTasks held, it also drops the parent job once it has no task left
+func (m *downloadManager) deleteTask(userId uint, taskId uint) {
+	task, ok := m.tasks[userId][taskId]
+	if !ok {
+		return
+	}
+	delete(m.tasks[userId], taskId)
+
+	job, ok := m.jobs[userId][task.jobId]
+	if !ok {
+		return
+	}
+	job.mu.Lock()
+	job.taskIds = slices.DeleteFunc(job.taskIds, func(id uint) bool { return id == taskId })
+	empty := len(job.taskIds) == 0 && !job.failed
+	job.mu.Unlock()
+	if empty {
+		delete(m.jobs[userId], task.jobId)
+	}
+}
+
+func (t *downloadTask) data(id uint) models.DownloadData {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return models.DownloadData{
+		Id:         id,
+		JobId:      t.jobId,
+		Data:       t.songData,
+		Provider:   t.provider,
+		Status:     t.status,
+		Error:      t.err,
+		ErrorClass: t.errClass,
+		Attempts:   t.attempts,
+		RetryAt:    t.retryAt,
+	}
+}
+
+// InFlight reports whether a song is waiting or downloading for the user
+func (m *downloadManager) InFlight(userId uint, provider string, songId string) bool {
 	m.mTasks.Lock()
-	tasks := make([]models.DownloadData, 0, len(m.tasks))
-	for id, task := range m.tasks[userId] {
+	defer m.mTasks.Unlock()
+	for _, task := range m.tasks[userId] {
 		task.mu.Lock()
-		tmp := models.DownloadData{
-			Id:       id,
-			Data:     task.songData,
-			Provider: task.provider,
-			Status:   task.status,
-		}
+		active := task.provider == provider && task.songId == songId &&
+			(task.status == models.StatusPending || task.status == models.StatusRunning)
 		task.mu.Unlock()
-		tasks = append(tasks, tmp)
+		if active {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *downloadManager) List(userId uint) []models.DownloadData {
+	m.mTasks.Lock()
+	tasks := make([]models.DownloadData, 0, len(m.tasks[userId]))
+	for id, task := range m.tasks[userId] {
+		tasks = append(tasks, task.data(id))
 	}
 	m.mTasks.Unlock()
 	return tasks
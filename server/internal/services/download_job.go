@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+type downloadJob struct {
+	mu       sync.Mutex
+	userId   uint
+	priority models.Priority
+	request  models.RequestDownload
+	title    string
+	taskIds  []uint
+	failed   bool
+	options  downloadOptions
+}
+
+func (m *downloadManager) generateJobId(userId uint) uint {
+	m.mIds.Lock()
+	id, ok := m.jobIds[userId]
+	if !ok {
+		id = 0
+	}
+	id++
+	m.jobIds[userId] = id
+	m.mIds.Unlock()
+
+	return id
+}
+
+func (m *downloadManager) addJob(userId uint, req models.RequestDownload, priority models.Priority, options downloadOptions) uint {
+	jobId := m.generateJobId(userId)
+
+	newJob := &downloadJob{
+		mu:       sync.Mutex{},
+		userId:   userId,
+		priority: priority,
+		request:  req,
+		taskIds:  make([]uint, 0),
+		options:  options,
+	}
+
+	m.mTasks.Lock()
+	userJobs, ok := m.jobs[userId]
+	if !ok {
+		userJobs = make(map[uint]*downloadJob)
+		m.jobs[userId] = userJobs
+	}
+	userJobs[jobId] = newJob
+	m.mTasks.Unlock()
+
+	m.resolveJob(jobId, newJob)
+
+	return jobId
+}
+
+func resolveRequest(ctx context.Context, userId uint, req models.RequestDownload) (string, []string, error) {
+	switch models.Type(req.Type) {
+	case models.TypeSong:
+		return "", []string{req.Id}, nil
+	case models.TypeAlbum:
+		album, err := plugins.GetAlbum(ctx, userId, req.Provider, req.Id)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolveRequest: %w", err)
+		}
+		songIds := make([]string, 0, len(album.Songs))
+		for _, song := range album.Songs {
+			songIds = append(songIds, song.Id)
+		}
+		return album.Title, songIds, nil
+	case models.TypePlaylist:
+		playlist, err := plugins.GetPlaylist(ctx, userId, req.Provider, req.Id)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolveRequest: %w", err)
+		}
+		songIds := make([]string, 0, len(playlist.Songs))
+		for _, song := range playlist.Songs {
+			songIds = append(songIds, song.Id)
+		}
+		return playlist.Title, songIds, nil
+	case models.TypeArtist:
+		artist, err := plugins.GetArtist(ctx, userId, req.Provider, req.Id)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolveRequest: %w", err)
+		}
+		songIds := make([]string, 0)
+		for _, item := range artist.Albums {
+			album, err := plugins.GetAlbum(ctx, userId, req.Provider, item.Id)
+			if err != nil {
+				log.Println("resolveRequest: ", err)
+				continue
+			}
+			for _, song := range album.Songs {
+				songIds = append(songIds, song.Id)
+			}
+		}
+		return artist.Name, songIds, nil
+	default:
+		return "", nil, fmt.Errorf("resolveRequest: %w", errors.New("invalid type"))
+	}
+}
+
+func (m *downloadManager) resolveJob(jobId uint, job *downloadJob) {
+	title, songIds, err := resolveRequest(context.Background(), job.userId, job.request)
+	job.mu.Lock()
+	if err != nil {
+		job.failed = true
+		job.mu.Unlock()
+		log.Println("downloadManager.resolveJob: ", err)
+		return
+	}
+	job.title = title
+	job.failed = false
+	job.mu.Unlock()
+
+	for _, songId := range songIds {
+		m.addTask(job.userId, jobId, job.request.Provider, songId, job.priority)
+	}
+}
+
+func (m *downloadManager) getJobTasks(userId uint, jobId uint) (*downloadJob, []*downloadTask, error) {
+	m.mTasks.Lock()
+	defer m.mTasks.Unlock()
+
+	job, ok := m.jobs[userId][jobId]
+	if !ok {
+		return nil, nil, errors.New("download job not found")
+	}
+
+	job.mu.Lock()
+	tasks := make([]*downloadTask, 0, len(job.taskIds))
+	for _, taskId := range job.taskIds {
+		if task, ok := m.tasks[userId][taskId]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	job.mu.Unlock()
+
+	return job, tasks, nil
+}
+
+// jobTaskDone marks the feed entry of an album once every song of its job is downloaded
+func (m *downloadManager) jobTaskDone(userId uint, jobId uint) {
+	job, tasks, err := m.getJobTasks(userId, jobId)
+	if err != nil || models.Type(job.request.Type) != models.TypeAlbum {
+		return
+	}
+
+	for _, task := range tasks {
+		task.mu.Lock()
+		status := task.status
+		task.mu.Unlock()
+		if status != models.StatusDone {
+			return
+		}
+	}
+
+	if err := repository.MarkFeedEntryDownloaded(userId, job.request.Provider, job.request.Id); err != nil {
+		log.Println("downloadManager.jobTaskDone: ", err)
+	}
+}
+
+func (m *downloadManager) RetryJob(userId uint, jobId uint) error {
+	job, tasks, err := m.getJobTasks(userId, jobId)
+	if err != nil {
+		return err
+	}
+
+	job.mu.Lock()
+	failed := job.failed
+	job.mu.Unlock()
+	if failed {
+		m.resolveJob(jobId, job)
+		return nil
+	}
+
+	for _, task := range tasks {
+		task.retry()
+	}
+	return nil
+}
+
+func (m *downloadManager) CancelJob(userId uint, jobId uint) error {
+	_, tasks, err := m.getJobTasks(userId, jobId)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		task.cancel()
+	}
+	return nil
+}
+
+func (m *downloadManager) RemoveJob(userId uint, jobId uint) error {
+	m.mTasks.Lock()
+	defer m.mTasks.Unlock()
+
+	job, ok := m.jobs[userId][jobId]
+	if !ok {
+		return errors.New("download job not found")
+	}
+
+	job.mu.Lock()
+	taskIds := slices.Clone(job.taskIds)
+	job.mu.Unlock()
+
+	for _, taskId := range taskIds {
+		if task, ok := m.tasks[userId][taskId]; ok {
+			task.cancel()
+			delete(m.tasks[userId], taskId)
+		}
+	}
+	delete(m.jobs[userId], jobId)
+	return nil
+}
+
+func (m *downloadManager) ListJobs(userId uint, expand bool) []models.DownloadJobData {
+	m.mTasks.Lock()
+	jobs := make([]models.DownloadJobData, 0, len(m.jobs[userId]))
+	for id, job := range m.jobs[userId] {
+		job.mu.Lock()
+		data := models.DownloadJobData{
+			Id:      id,
+			Request: job.request,
+			Title:   job.title,
+			Total:   len(job.taskIds),
+		}
+		failed := job.failed
+		taskIds := slices.Clone(job.taskIds)
+		job.mu.Unlock()
+
+		for _, taskId := range taskIds {
+			task, ok := m.tasks[userId][taskId]
+			if !ok {
+				continue
+			}
+			taskData := task.data(taskId)
+			switch taskData.Status {
+			case models.StatusPending:
+				data.Pending++
+			case models.StatusRunning:
+				data.Running++
+			case models.StatusDone:
+				data.Done++
+			case models.StatusFailed:
+				data.Failed++
+			case models.StatusCancel:
+				data.Cancel++
+			}
+			if data.Title == "" {
+				data.Title = taskData.Data.Title
+			}
+			if expand {
+				data.Tasks = append(data.Tasks, taskData)
+			}
+		}
+
+		switch {
+		case failed:
+			data.Status = models.StatusFailed
+		case data.Running > 0:
+			data.Status = models.StatusRunning
+		case data.Pending > 0:
+			data.Status = models.StatusPending
+		case data.Failed > 0:
+			data.Status = models.StatusFailed
+		case data.Cancel > 0:
+			data.Status = models.StatusCancel
+		default:
+			data.Status = models.StatusDone
+		}
+		if data.Total > 0 {
+			data.Progress = float64(data.Done) / float64(data.Total)
+		}
+
+		jobs = append(jobs, data)
+	}
+	m.mTasks.Unlock()
+
+	slices.SortFunc(jobs, func(a, b models.DownloadJobData) int {
+		return int(b.Id) - int(a.Id)
+	})
+	return jobs
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+)
+
+// maxRetryDelay bounds the doubling of the delay, so late attempts neither wait for days nor overflow
+const maxRetryDelay = time.Hour
+
+type downloadRetryPolicy struct {
+	mu    sync.Mutex
+	limit uint
+	delay time.Duration
+}
+
+func newDownloadRetryPolicy() *downloadRetryPolicy {
+	return &downloadRetryPolicy{
+		mu:    sync.Mutex{},
+		limit: 3,
+		delay: 30 * time.Second,
+	}
+}
+
+func (p *downloadRetryPolicy) set(limit uint, delay time.Duration) {
+	p.mu.Lock()
+	p.limit = limit
+	p.delay = delay
+	p.mu.Unlock()
+}
+
+// backoff returns the delay before the next automatic retry, doubling at each attempt up to maxRetryDelay
+func (p *downloadRetryPolicy) backoff(attempts uint, class models.ErrorClass) (time.Duration, bool) {
+	if class != models.ErrorNetwork && class != models.ErrorInstance {
+		return 0, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if attempts >= p.limit {
+		return 0, false
+	}
+	delay := p.delay
+	for range attempts {
+		if delay >= maxRetryDelay {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay), true
+}
+
+func classifyDownloadError(err error) models.ErrorClass {
+	var statusErr *utils.StatusError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, models.ErrQualityMismatch):
+		return models.ErrorQuality
+	case errors.Is(err, models.ErrNotAvailable):
+		return models.ErrorRegion
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.As(err, &netErr):
+		return models.ErrorNetwork
+	case errors.As(err, &statusErr):
+		return models.ErrorInstance
+	case errors.Is(err, models.ErrTagging):
+		return models.ErrorTagging
+	default:
+		return models.ErrorUnknown
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+)
+
+func TestClassifyDownloadError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.ErrorClass
+	}{
+		{"quality mismatch", fmt.Errorf("hifi.Download: %w", models.ErrQualityMismatch), models.ErrorQuality},
+		{"not available", fmt.Errorf("hifi.Download: %w", models.ErrNotAvailable), models.ErrorRegion},
+		{"deadline", fmt.Errorf("hifi.Download: %w", context.DeadlineExceeded), models.ErrorNetwork},
+		{"cut body", fmt.Errorf("io.Copy: %w", io.ErrUnexpectedEOF), models.ErrorNetwork},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), models.ErrorNetwork},
+		{"net error", &net.OpError{Op: "dial", Err: errors.New("refused")}, models.ErrorNetwork},
+		{"bad gateway", fmt.Errorf("hifi.Download: %w", &utils.StatusError{Code: 502, Status: "502 Bad Gateway"}), models.ErrorInstance},
+		{"not found", fmt.Errorf("hifi.Download: %w", &utils.StatusError{Code: 404, Status: "404 Not Found"}), models.ErrorInstance},
+		{"tagging", fmt.Errorf("saveSong: %w", models.ErrTagging), models.ErrorTagging},
+		{"unknown", errors.New("boom"), models.ErrorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyDownloadError(tt.err); got != tt.want {
+				t.Errorf("classifyDownloadError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     uint
+		delay     time.Duration
+		attempts  uint
+		class     models.ErrorClass
+		want      time.Duration
+		wantRetry bool
+	}{
+		{"first retry", 3, 30 * time.Second, 0, models.ErrorNetwork, 30 * time.Second, true},
+		{"doubles", 3, 30 * time.Second, 2, models.ErrorInstance, 2 * time.Minute, true},
+		{"limit reached", 3, 30 * time.Second, 3, models.ErrorNetwork, 0, false},
+		{"no retry", 0, 30 * time.Second, 0, models.ErrorNetwork, 0, false},
+		{"capped", 10, 30 * time.Second, 9, models.ErrorNetwork, maxRetryDelay, true},
+		{"delay above the cap", 10, 2 * time.Hour, 0, models.ErrorNetwork, maxRetryDelay, true},
+		{"no overflow", 200, time.Minute, 150, models.ErrorNetwork, maxRetryDelay, true},
+		{"region", 3, 30 * time.Second, 0, models.ErrorRegion, 0, false},
+		{"quality", 3, 30 * time.Second, 0, models.ErrorQuality, 0, false},
+		{"tagging", 3, 30 * time.Second, 0, models.ErrorTagging, 0, false},
+		{"unknown", 3, 30 * time.Second, 0, models.ErrorUnknown, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newDownloadRetryPolicy()
+			p.set(tt.limit, tt.delay)
+			got, retry := p.backoff(tt.attempts, tt.class)
+			if got != tt.want || retry != tt.wantRetry {
+				t.Errorf("backoff(%d, %q) = %v, %t, want %v, %t", tt.attempts, tt.class, got, retry, tt.want, tt.wantRetry)
+			}
+		})
+	}
+}
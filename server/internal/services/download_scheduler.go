@@ -0,0 +1,138 @@
+package services
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+type downloadScheduler struct {
+	mu          sync.Mutex
+	globalLimit int
+	userLimit   int
+	running     int
+	userRunning map[uint]int
+	queues      map[uint][]*downloadTask
+	users       []uint
+	next        int
+	start       func(t *downloadTask)
+}
+
+// newDownloadScheduler returns a scheduler handing every dispatched task to start, which must not block
+func newDownloadScheduler(start func(t *downloadTask)) *downloadScheduler {
+	return &downloadScheduler{
+		mu:          sync.Mutex{},
+		globalLimit: 3,
+		userLimit:   3,
+		userRunning: make(map[uint]int),
+		queues:      make(map[uint][]*downloadTask),
+		users:       make([]uint, 0),
+		start:       start,
+	}
+}
+
+func (s *downloadScheduler) setLimits(globalLimit uint, userLimit uint) {
+	s.mu.Lock()
+	s.globalLimit = int(globalLimit)
+	s.userLimit = int(userLimit)
+	s.dispatch()
+	s.mu.Unlock()
+}
+
+func (s *downloadScheduler) enqueue(t *downloadTask) {
+	s.mu.Lock()
+	if !slices.Contains(s.users, t.userId) {
+		s.users = append(s.users, t.userId)
+	}
+	s.queues[t.userId] = append(s.queues[t.userId], t)
+	s.dispatch()
+	s.mu.Unlock()
+}
+
+func (s *downloadScheduler) remove(t *downloadTask) {
+	s.mu.Lock()
+	s.queues[t.userId] = slices.DeleteFunc(s.queues[t.userId], func(task *downloadTask) bool {
+		return task == t
+	})
+	s.mu.Unlock()
+}
+
+func (s *downloadScheduler) release(t *downloadTask) {
+	s.mu.Lock()
+	s.running--
+	s.userRunning[t.userId]--
+	s.dispatch()
+	s.mu.Unlock()
+}
+
+// bestTask returns the index of the oldest task with the highest priority in the user queue
+func (s *downloadScheduler) bestTask(userId uint) int {
+	best := -1
+	for i, task := range s.queues[userId] {
+		if best == -1 || task.priority > s.queues[userId][best].priority {
+			best = i
+		}
+	}
+	return best
+}
+
+// dispatch must be called with mu held, it starts queued tasks while slots are free,
+// serving users in turn among those whose next task has the highest priority
+func (s *downloadScheduler) dispatch() {
+	for s.running < s.globalLimit {
+		s.users = slices.DeleteFunc(s.users, func(userId uint) bool {
+			if len(s.queues[userId]) != 0 || s.userRunning[userId] > 0 {
+				return false
+			}
+			delete(s.queues, userId)
+			delete(s.userRunning, userId)
+			return true
+		})
+		if len(s.users) == 0 {
+			return
+		}
+		if s.next >= len(s.users) {
+			s.next = 0
+		}
+
+		candidates := make(map[uint]int)
+		for _, userId := range s.users {
+			if s.userRunning[userId] >= s.userLimit {
+				continue
+			}
+			if index := s.bestTask(userId); index != -1 {
+				candidates[userId] = index
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+
+		var priority models.Priority
+		first := true
+		for userId, index := range candidates {
+			if taskPriority := s.queues[userId][index].priority; first || taskPriority > priority {
+				priority = taskPriority
+				first = false
+			}
+		}
+
+		for i := range s.users {
+			position := (s.next + i) % len(s.users)
+			userId := s.users[position]
+			index, ok := candidates[userId]
+			if !ok || s.queues[userId][index].priority != priority {
+				continue
+			}
+
+			task := s.queues[userId][index]
+			s.queues[userId] = slices.Delete(s.queues[userId], index, index+1)
+			s.running++
+			s.userRunning[userId]++
+			s.next = position + 1
+			s.start(task)
+			break
+		}
+	}
+}
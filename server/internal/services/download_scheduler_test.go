@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+type scheduledTask struct {
+	userId   uint
+	priority models.Priority
+}
+
+func TestDownloadSchedulerBestTask(t *testing.T) {
+	low, high := models.PriorityAutoFetch, models.PriorityManual
+	tests := []struct {
+		name       string
+		priorities []models.Priority
+		want       int
+	}{
+		{"empty queue", nil, -1},
+		{"single task", []models.Priority{low}, 0},
+		{"oldest of the same priority", []models.Priority{low, low, low}, 0},
+		{"highest priority first", []models.Priority{low, high, low}, 1},
+		{"oldest of the highest priority", []models.Priority{low, high, high}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDownloadScheduler(func(*downloadTask) {})
+			for _, priority := range tt.priorities {
+				s.queues[1] = append(s.queues[1], &downloadTask{userId: 1, priority: priority})
+			}
+			if got := s.bestTask(1); got != tt.want {
+				t.Errorf("bestTask() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDownloadSchedulerDispatch queues every task with no free slot, opens the limits and then releases
+// each started task in turn, the tasks must start in the wanted order
+func TestDownloadSchedulerDispatch(t *testing.T) {
+	low, high := models.PriorityAutoFetch, models.PriorityManual
+	tests := []struct {
+		name        string
+		globalLimit uint
+		userLimit   uint
+		tasks       []scheduledTask
+		// wantFirst lists the tasks started once the limits are set, before any release
+		wantFirst []string
+		want      []string
+	}{
+		{
+			name:        "users take turns",
+			globalLimit: 1,
+			userLimit:   1,
+			tasks:       []scheduledTask{{1, low}, {1, low}, {1, low}, {2, low}, {2, low}},
+			wantFirst:   []string{"1.0"},
+			want:        []string{"1.0", "2.0", "1.1", "2.1", "1.2"},
+		},
+		{
+			name:        "priority before turns",
+			globalLimit: 1,
+			userLimit:   1,
+			tasks:       []scheduledTask{{1, low}, {1, low}, {2, high}},
+			wantFirst:   []string{"2.0"},
+			want:        []string{"2.0", "1.0", "1.1"},
+		},
+		{
+			name:        "priority inside a user queue",
+			globalLimit: 1,
+			userLimit:   1,
+			tasks:       []scheduledTask{{1, low}, {1, high}, {1, low}},
+			wantFirst:   []string{"1.1"},
+			want:        []string{"1.1", "1.0", "1.2"},
+		},
+		{
+			name:        "user limit leaves slots to the others",
+			globalLimit: 3,
+			userLimit:   1,
+			tasks:       []scheduledTask{{1, low}, {1, low}, {1, low}, {2, low}},
+			wantFirst:   []string{"1.0", "2.0"},
+			want:        []string{"1.0", "2.0", "1.1", "1.2"},
+		},
+		{
+			name:        "global limit",
+			globalLimit: 2,
+			userLimit:   3,
+			tasks:       []scheduledTask{{1, low}, {1, low}, {1, low}},
+			wantFirst:   []string{"1.0", "1.1"},
+			want:        []string{"1.0", "1.1", "1.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := make(map[*downloadTask]string)
+			started := make([]*downloadTask, 0)
+			s := newDownloadScheduler(func(task *downloadTask) {
+				started = append(started, task)
+			})
+
+			s.setLimits(0, tt.userLimit)
+			counts := make(map[uint]int)
+			for _, task := range tt.tasks {
+				queued := &downloadTask{userId: task.userId, priority: task.priority}
+				names[queued] = fmt.Sprintf("%d.%d", task.userId, counts[task.userId])
+				counts[task.userId]++
+				s.enqueue(queued)
+			}
+			if len(started) != 0 {
+				t.Fatalf("%d tasks started without a free slot", len(started))
+			}
+
+			s.setLimits(tt.globalLimit, tt.userLimit)
+			got := make([]string, 0, len(started))
+			for _, task := range started {
+				got = append(got, names[task])
+			}
+			if !slices.Equal(got, tt.wantFirst) {
+				t.Errorf("first dispatch = %v, want %v", got, tt.wantFirst)
+			}
+
+			for i := 0; i < len(started); i++ {
+				s.release(started[i])
+			}
+			got = got[:0]
+			for _, task := range started {
+				got = append(got, names[task])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("start order = %v, want %v", got, tt.want)
+			}
+			if s.running != 0 {
+				t.Errorf("running = %d after every release, want 0", s.running)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+)
+
+const (
+	// MaxDownloadUrlsSize bounds the pasted text and the uploaded file of a single request
+	MaxDownloadUrlsSize = 1 << 20
+	// each url costs a plugin lookup while the request waits
+	maxDownloadUrls = 200
+)
+
+var urlPattern = regexp.MustCompile(`(?i)https?://[^\s<>"'()]+`)
+
+// ExtractUrls finds the links of a pasted text, a line without any http link is taken as a whole
+func ExtractUrls(text string) []string {
+	urls := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		found := urlPattern.FindAllString(line, -1)
+		if len(found) == 0 {
+			found = []string{line}
+		}
+		for _, url := range found {
+			url = strings.TrimRight(url, ".,;")
+			if !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// resolveUrl returns the item of the first plugin recognizing the url, or the error of the last one tried
+func resolveUrl(ctx context.Context, userId uint, url string) (models.UrlItem, error) {
+	err := errors.New("no provider recognizes this url")
+	for _, pluginsList := range plugins.GetAllPluginsByProvider() {
+		for _, plugin := range pluginsList {
+			var item models.UrlItem
+			if item, err = plugin.Url(ctx, userId, url); err == nil {
+				return item, nil
+			}
+		}
+	}
+	return models.UrlItem{}, err
+}
+
+func (m *downloadManager) addItem(userId uint, item models.UrlItem, priority models.Priority) error {
+	switch item.Type {
+	case models.TypeSong:
+		m.AddSong(userId, item.Provider, item.Id, priority)
+	case models.TypeAlbum:
+		m.AddAlbum(userId, item.Provider, item.Id, priority)
+	case models.TypeArtist:
+		m.AddArtist(userId, item.Provider, item.Id, priority)
+	case models.TypePlaylist:
+		m.AddPlaylist(userId, item.Provider, item.Id, priority)
+	default:
+		return fmt.Errorf("invalid type %q", item.Type)
+	}
+	return nil
+}
+
+// QueueUrls resolves every url through the plugins and queues what it points to
+func QueueUrls(ctx context.Context, userId uint, urls []string) (models.ResponseDownloadUrls, error) {
+	if len(urls) > maxDownloadUrls {
+		return models.ResponseDownloadUrls{}, fmt.Errorf("services.QueueUrls: %w", fmt.Errorf("%d urls, at most %d can be queued at once", len(urls), maxDownloadUrls))
+	}
+	result := models.ResponseDownloadUrls{Results: make([]models.DownloadUrlResult, 0, len(urls))}
+	for _, url := range urls {
+		item, err := resolveUrl(ctx, userId, url)
+		if err == nil {
+			err = DownloadManager.addItem(userId, item, models.PriorityManual)
+		}
+		if err != nil {
+			result.Failed++
+			result.Results = append(result.Results, models.DownloadUrlResult{Url: url, Error: err.Error()})
+			continue
+		}
+		result.Queued++
+		result.Results = append(result.Results, models.DownloadUrlResult{Url: url, Item: &item})
+	}
+	return result, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+)
+
+const (
+	externalSearchWorkers = 4
+	externalMatchScore    = 0.85
+	externalMinimumScore  = 0.5
+	externalMargin        = 0.1
+	externalCandidates    = 5
+	// each row costs one or two provider searches while the request waits
+	externalMaxRows = 500
+)
+
+var externalNoise = regexp.MustCompile(`(?i)\s*[\(\[](feat\.?|ft\.?|with|remaster(ed)?|[0-9]{4} remaster(ed)?)[^\)\]]*[\)\]]|\s+-\s+([0-9]{4} )?remaster(ed)?.*$`)
+
+// normalizeText lowercases and drops featurings, remaster notes and punctuation
+func normalizeText(s string) string {
+	s = externalNoise.ReplaceAllString(strings.ToLower(s), "")
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// similarity is the best of the edit distance ratio and the shared words ratio of the normalized texts
+func similarity(a, b string) float64 {
+	a, b = normalizeText(a), normalizeText(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	ratio := 1 - float64(levenshtein(ra, rb))/float64(max(len(ra), len(rb)))
+
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	shared := 0
+	for _, word := range wordsA {
+		for _, other := range wordsB {
+			if word == other {
+				shared++
+				break
+			}
+		}
+	}
+	dice := 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+	return math.Max(ratio, dice)
+}
+
+// scoreCandidate weighs title, artist and duration, the duration weight goes to the title when the row has none
+func scoreCandidate(entry playlist.Entry, song models.SearchDataSong) float64 {
+	title := similarity(entry.Title, song.Title)
+
+	artist := 0.0
+	for _, name := range strings.Split(entry.Artist, ",") {
+		for _, songArtist := range song.Artists {
+			artist = math.Max(artist, similarity(name, songArtist.Name))
+		}
+	}
+	if entry.Artist == "" {
+		artist = 0.5
+	}
+
+	if entry.Duration == 0 || song.Duration == 0 {
+		return 0.65*title + 0.35*artist
+	}
+	gap := math.Abs(float64(entry.Duration) - float64(song.Duration))
+	duration := math.Max(0, 1-math.Max(0, gap-2)/13)
+	return 0.5*title + 0.35*artist + 0.15*duration
+}
+
+func externalCandidate(song models.SearchDataSong, score float64) models.ExternalCandidate {
+	artists := make([]string, 0, len(song.Artists))
+	for _, artist := range song.Artists {
+		artists = append(artists, artist.Name)
+	}
+	return models.ExternalCandidate{
+		Id:       song.Id,
+		Title:    song.Title,
+		Artists:  artists,
+		Album:    song.Album.Title,
+		Duration: song.Duration,
+		Isrc:     song.Isrc,
+		CoverUrl: song.Album.CoverUrl,
+		Score:    math.Round(score*100) / 100,
+	}
+}
+
+func searchSongs(ctx context.Context, userId uint, pluginsList []models.Plugin, query string) []models.SearchDataSong {
+	for _, plugin := range pluginsList {
+		data, err := plugin.Search(ctx, userId, models.SearchQuery{Song: query})
+		if err == nil {
+			return data.Songs
+		}
+	}
+	return nil
+}
+
+// matchExternalRow resolves a row by exact isrc first, then by the best fuzzy score of a title and artist search
+func matchExternalRow(ctx context.Context, userId uint, pluginsList []models.Plugin, index int, entry playlist.Entry) models.ExternalRow {
+	row := models.ExternalRow{
+		Index:      index,
+		Title:      entry.Title,
+		Artist:     entry.Artist,
+		Album:      entry.Album,
+		Isrc:       entry.Isrc,
+		Duration:   entry.Duration,
+		Status:     models.ExternalUnmatched,
+		Candidates: make([]models.ExternalCandidate, 0),
+	}
+
+	if entry.Isrc != "" {
+		for _, song := range searchSongs(ctx, userId, pluginsList, entry.Isrc) {
+			if strings.EqualFold(song.Isrc, entry.Isrc) {
+				match := externalCandidate(song, 1)
+				row.Status = models.ExternalMatched
+				row.Match = &match
+				return row
+			}
+		}
+	}
+
+	if entry.Title == "" {
+		return row
+	}
+	query := strings.TrimSpace(entry.Artist + " " + entry.Title)
+	if artists := strings.Split(entry.Artist, ","); len(artists) > 1 {
+		query = strings.TrimSpace(artists[0]) + " " + entry.Title
+	}
+
+	for _, song := range searchSongs(ctx, userId, pluginsList, query) {
+		if score := scoreCandidate(entry, song); score >= externalMinimumScore {
+			row.Candidates = append(row.Candidates, externalCandidate(song, score))
+		}
+	}
+	sort.SliceStable(row.Candidates, func(i, j int) bool {
+		return row.Candidates[i].Score > row.Candidates[j].Score
+	})
+	if len(row.Candidates) > externalCandidates {
+		row.Candidates = row.Candidates[:externalCandidates]
+	}
+
+	switch {
+	case len(row.Candidates) == 0:
+	case row.Candidates[0].Score >= externalMatchScore &&
+		(len(row.Candidates) == 1 || row.Candidates[0].Score-row.Candidates[1].Score >= externalMargin):
+		match := row.Candidates[0]
+		row.Status = models.ExternalMatched
+		row.Match = &match
+		row.Candidates = row.Candidates[:0]
+	default:
+		row.Status = models.ExternalAmbiguous
+	}
+	return row
+}
+
+// MatchExternalPlaylist resolves every row of an exported playlist to a song of the provider for review
+func MatchExternalPlaylist(ctx context.Context, userId uint, provider string, filename string, reader io.Reader) (models.ResponseExternalImport, error) {
+	pluginsList, ok := plugins.GetPluginByProvider(provider)
+	if !ok {
+		return models.ResponseExternalImport{}, fmt.Errorf("services.MatchExternalPlaylist: %w", errors.New("invalid provider name"))
+	}
+	format, ok := playlist.FormatOf(filename)
+	if !ok {
+		return models.ResponseExternalImport{}, fmt.Errorf("services.MatchExternalPlaylist: %w", fmt.Errorf("unsupported playlist file %q", filename))
+	}
+	name, entries, err := playlist.Parse(format, reader)
+	if err != nil {
+		return models.ResponseExternalImport{}, fmt.Errorf("services.MatchExternalPlaylist: %w", err)
+	}
+	if len(entries) > externalMaxRows {
+		return models.ResponseExternalImport{}, fmt.Errorf("services.MatchExternalPlaylist: %w", fmt.Errorf("%d rows, at most %d can be imported at once", len(entries), externalMaxRows))
+	}
+
+	result := models.ResponseExternalImport{
+		Provider: provider,
+		Name:     name,
+		Rows:     make([]models.ExternalRow, len(entries)),
+	}
+
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for range externalSearchWorkers {
+		wg.Go(func() {
+			for index := range indexes {
+				result.Rows[index] = matchExternalRow(ctx, userId, pluginsList, index, entries[index])
+			}
+		})
+	}
+	for index := range entries {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case models.ExternalMatched:
+			result.Matched++
+		case models.ExternalAmbiguous:
+			result.Ambiguous++
+		default:
+			result.Unmatched++
+		}
+	}
+	return result, nil
+}
+
+// QueueExternalSongs queues the songs confirmed by the user
+func QueueExternalSongs(userId uint, provider string, ids []string) (int, error) {
+	if _, ok := plugins.GetPluginByProvider(provider); !ok {
+		return 0, fmt.Errorf("services.QueueExternalSongs: %w", errors.New("invalid provider name"))
+	}
+	queued := 0
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		DownloadManager.AddSong(userId, provider, id, models.PriorityManual)
+		queued++
+	}
+	return queued, nil
+}
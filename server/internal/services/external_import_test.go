@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+)
+
+func searchSong(title string, duration uint, artists ...string) models.SearchDataSong {
+	song := models.SearchDataSong{Title: title, Duration: duration}
+	for _, name := range artists {
+		song.Artists = append(song.Artists, models.SongDataArtist{Name: name})
+	}
+	return song
+}
+
+func TestScoreCandidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry playlist.Entry
+		song  models.SearchDataSong
+		min   float64
+		max   float64
+	}{
+		{
+			name:  "exact",
+			entry: playlist.Entry{Title: "Song", Artist: "Band", Duration: 215},
+			song:  searchSong("Song", 215, "Band"),
+			min:   1,
+			max:   1,
+		},
+		{
+			name:  "featuring and remaster notes",
+			entry: playlist.Entry{Title: "Song (feat. Other) - 2011 Remaster", Artist: "Band", Duration: 215},
+			song:  searchSong("Song", 216, "Band"),
+			min:   1,
+			max:   1,
+		},
+		{
+			name:  "second artist of the row",
+			entry: playlist.Entry{Title: "Song", Artist: "Other, Band", Duration: 215},
+			song:  searchSong("Song", 215, "Band"),
+			min:   1,
+			max:   1,
+		},
+		{
+			name:  "duration gap past the tolerance",
+			entry: playlist.Entry{Title: "Song", Artist: "Band", Duration: 215},
+			song:  searchSong("Song", 245, "Band"),
+			min:   0.85,
+			max:   0.85,
+		},
+		{
+			name:  "no duration moves its weight to the title",
+			entry: playlist.Entry{Title: "Song", Artist: "Band"},
+			song:  searchSong("Song", 215, "Band"),
+			min:   1,
+			max:   1,
+		},
+		{
+			name:  "no artist in the row",
+			entry: playlist.Entry{Title: "Song"},
+			song:  searchSong("Song", 215, "Band"),
+			min:   0.825,
+			max:   0.825,
+		},
+		{
+			name:  "other artist",
+			entry: playlist.Entry{Title: "Song", Artist: "Band", Duration: 215},
+			song:  searchSong("Song", 215, "Unrelated Orchestra"),
+			min:   0.65,
+			max:   externalMatchScore,
+		},
+		{
+			name:  "other song",
+			entry: playlist.Entry{Title: "Yesterday", Artist: "Band", Duration: 125},
+			song:  searchSong("Bohemian Rhapsody", 354, "Queen"),
+			max:   externalMinimumScore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreCandidate(tt.entry, tt.song)
+			if got < tt.min-1e-9 || got > tt.max+1e-9 {
+				t.Errorf("scoreCandidate() = %.3f, want between %.3f and %.3f", got, tt.min, tt.max)
+			}
+		})
+	}
+}
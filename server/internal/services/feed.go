@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+)
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Links   []atomLink  `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// GetFeedToken returns the feed token of the user, empty until one is created by RenewFeedToken
+func GetFeedToken(userId uint) (string, error) {
+	user, err := repository.GetUserByID(userId)
+	if err != nil {
+		return "", fmt.Errorf("services.GetFeedToken: %w", err)
+	}
+	if user.FeedToken == nil {
+		return "", nil
+	}
+	return *user.FeedToken, nil
+}
+
+// RenewFeedToken generates a new feed token, the previous feed url stops working
+func RenewFeedToken(userId uint) (string, error) {
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", fmt.Errorf("services.RenewFeedToken: %w", err)
+	}
+	if err := repository.UpdateUserFeedToken(userId, token); err != nil {
+		return "", fmt.Errorf("services.RenewFeedToken: %w", err)
+	}
+	return token, nil
+}
+
+func BuildAtomFeed(selfUrl string, entries []models.FeedEntry) ([]byte, error) {
+	feed := atomFeed{
+		Title:   "MusicShack new releases",
+		Id:      selfUrl,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Links:   []atomLink{{Href: selfUrl, Rel: "self", Type: "application/atom+xml"}},
+		Entries: make([]atomEntry, 0, len(entries)),
+	}
+	// the entries are sorted by release date, the feed changes with the latest insert
+	if len(entries) > 0 {
+		updated := entries[0].CreatedAt
+		for _, entry := range entries[1:] {
+			if entry.CreatedAt.After(updated) {
+				updated = entry.CreatedAt
+			}
+		}
+		feed.Updated = updated.UTC().Format(time.RFC3339)
+	}
+
+	for _, entry := range entries {
+		status := "not queued"
+		if entry.Downloaded {
+			status = "downloaded"
+		} else if entry.Queued {
+			status = "queued"
+		}
+
+		item := atomEntry{
+			Title:   fmt.Sprintf("%s - %s", entry.ArtistName, entry.Title),
+			Id:      fmt.Sprintf("urn:musicshack:%s:%s", entry.Provider, entry.AlbumId),
+			Updated: entry.CreatedAt.UTC().Format(time.RFC3339),
+			Author:  entry.ArtistName,
+			Links:   make([]atomLink, 0, 1),
+			Content: atomContent{
+				Type: "html",
+				Body: fmt.Sprintf("<p>%s %s released on %s, %s</p>", entry.Type, xmlEscape(entry.Title), entry.ReleaseDate, status),
+			},
+		}
+		if entry.CoverUrl != "" {
+			item.Links = append(item.Links, atomLink{Href: entry.CoverUrl, Rel: "enclosure", Type: "image/jpeg"})
+			item.Content.Body += fmt.Sprintf(`<img src="%s"/>`, xmlEscape(entry.CoverUrl))
+		}
+		feed.Entries = append(feed.Entries, item)
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("services.BuildAtomFeed: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+func ValidateFollowFilters(filters models.FollowFilters) error {
+	for _, releaseType := range filters.ReleaseTypes {
+		if !slices.Contains([]models.ReleaseType{models.ReleaseAlbum, models.ReleaseEp, models.ReleaseSingle}, releaseType) {
+			return fmt.Errorf("ValidateFollowFilters: %w", fmt.Errorf("invalid release type %q", releaseType))
+		}
+	}
+	if !slices.Contains([]models.ExplicitPreference{models.ExplicitAny, models.ExplicitExplicit, models.ExplicitClean}, filters.Explicit) {
+		return fmt.Errorf("ValidateFollowFilters: %w", fmt.Errorf("invalid explicit preference %q", filters.Explicit))
+	}
+	for _, pattern := range slices.Concat(filters.IncludePatterns, filters.ExcludePatterns) {
+		if pattern == "" {
+			return fmt.Errorf("ValidateFollowFilters: %w", errors.New("empty pattern"))
+		}
+		if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+			return fmt.Errorf("ValidateFollowFilters: %w", err)
+		}
+	}
+	if filters.MinimumQuality != "" && !filters.MinimumQuality.Valid() {
+		return fmt.Errorf("ValidateFollowFilters: %w", fmt.Errorf("invalid minimum quality %q", filters.MinimumQuality))
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/metadata"
+)
+
+func newFollowImport() models.ResponseFollowImport {
+	return models.ResponseFollowImport{
+		Created:   make([]models.FollowItem, 0),
+		Skipped:   make([]models.FollowImportSkipped, 0),
+		Unmatched: make([]string, 0),
+		Ambiguous: make([]models.FollowImportAmbiguous, 0),
+	}
+}
+
+// importFollow follows an artist unless the user already does
+func importFollow(ctx context.Context, userId uint, provider string, artistId string, result *models.ResponseFollowImport) {
+	if follow, err := repository.GetFollowByUserIDByProviderByArtistID(userId, provider, artistId); err == nil {
+		result.Skipped = append(result.Skipped, models.FollowImportSkipped{Provider: provider, ArtistId: artistId, Name: follow.ArtistName, Reason: "already followed"})
+		return
+	}
+
+	artist, err := plugins.GetArtist(ctx, userId, provider, artistId)
+	if err != nil {
+		result.Skipped = append(result.Skipped, models.FollowImportSkipped{Provider: provider, ArtistId: artistId, Reason: err.Error()})
+		return
+	}
+
+	follow, err := repository.AddFollow(userId, provider, artistId, artist.Name, artist.PictureUrl)
+	if err != nil {
+		result.Skipped = append(result.Skipped, models.FollowImportSkipped{Provider: provider, ArtistId: artistId, Name: artist.Name, Reason: err.Error()})
+		return
+	}
+
+	result.Created = append(result.Created, models.FollowItem{
+		Id:               follow.ID,
+		Provider:         follow.Provider,
+		ArtistId:         follow.ArtistId,
+		ArtistName:       follow.ArtistName,
+		ArtistPictureUrl: follow.ArtistPictureUrl,
+		LastFetchedAt:    follow.LastFetchedAt,
+		Filters:          follow.Filters,
+	})
+}
+
+func libraryAlbumArtists(userId uint) ([]string, error) {
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return nil, fmt.Errorf("libraryAlbumArtists: %w", err)
+	}
+	songs, err := repository.ListSongByUserID(userId, "", "", -1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("libraryAlbumArtists: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, song := range songs {
+		tags, err := metadata.ReadTags(filepath.Join(userPath, song.Path))
+		if err != nil {
+			log.Println("libraryAlbumArtists:", err)
+			continue
+		}
+		for _, name := range tags[models.TagAlbumArtists] {
+			name = strings.TrimSpace(name)
+			key := strings.ToLower(name)
+			if name == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ImportFollowsFromLibrary follows the album artists of the library, a name is matched when the provider
+// returns a single artist with the exact same name, the other candidates are left to the user
+func ImportFollowsFromLibrary(ctx context.Context, userId uint, provider string) (models.ResponseFollowImport, error) {
+	pluginsList, ok := plugins.GetPluginByProvider(provider)
+	if !ok {
+		return models.ResponseFollowImport{}, fmt.Errorf("services.ImportFollowsFromLibrary: %w", errors.New("invalid provider name"))
+	}
+
+	names, err := libraryAlbumArtists(userId)
+	if err != nil {
+		return models.ResponseFollowImport{}, fmt.Errorf("services.ImportFollowsFromLibrary: %w", err)
+	}
+
+	result := newFollowImport()
+	for _, name := range names {
+		var search models.SearchData
+		for _, plugin := range pluginsList {
+			if search, err = plugin.Search(ctx, userId, models.SearchQuery{Artist: name}); err == nil {
+				break
+			}
+		}
+		if err != nil || len(search.Artists) == 0 {
+			result.Unmatched = append(result.Unmatched, name)
+			continue
+		}
+
+		exact := make([]models.SearchDataArtist, 0)
+		for _, artist := range search.Artists {
+			if strings.EqualFold(strings.TrimSpace(artist.Name), name) {
+				exact = append(exact, artist)
+			}
+		}
+
+		switch len(exact) {
+		case 1:
+			importFollow(ctx, userId, provider, exact[0].Id, &result)
+		case 0:
+			result.Ambiguous = append(result.Ambiguous, models.FollowImportAmbiguous{Name: name, Candidates: search.Artists[:min(len(search.Artists), 5)]})
+		default:
+			result.Ambiguous = append(result.Ambiguous, models.FollowImportAmbiguous{Name: name, Candidates: exact})
+		}
+	}
+	return result, nil
+}
+
+// ImportFollowsFromPlaylist follows the primary artist of every song of a provider playlist
+func ImportFollowsFromPlaylist(ctx context.Context, userId uint, provider string, playlistId string) (models.ResponseFollowImport, error) {
+	playlist, err := plugins.GetPlaylist(ctx, userId, provider, playlistId)
+	if err != nil {
+		return models.ResponseFollowImport{}, fmt.Errorf("services.ImportFollowsFromPlaylist: %w", err)
+	}
+
+	result := newFollowImport()
+	seen := make(map[string]bool)
+	for _, song := range playlist.Songs {
+		if len(song.Artists) == 0 {
+			result.Unmatched = append(result.Unmatched, song.Title)
+			continue
+		}
+		artist := song.Artists[0]
+		if seen[artist.Id] {
+			continue
+		}
+		seen[artist.Id] = true
+		importFollow(ctx, userId, provider, artist.Id, &result)
+	}
+	return result, nil
+}
+
+// ConfirmFollows follows the artists picked by the user among ambiguous matches
+func ConfirmFollows(ctx context.Context, userId uint, follows []models.RequestFollow) (models.ResponseFollowImport, error) {
+	result := newFollowImport()
+	for _, follow := range follows {
+		if _, ok := plugins.GetPluginByProvider(follow.Provider); !ok {
+			return models.ResponseFollowImport{}, fmt.Errorf("services.ConfirmFollows: %w", fmt.Errorf("invalid provider name %q", follow.Provider))
+		}
+	}
+	for _, follow := range follows {
+		importFollow(ctx, userId, follow.Provider, follow.Id, &result)
+	}
+	return result, nil
+}
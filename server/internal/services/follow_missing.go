@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+// missingRelease compares the tracks of a release with the isrcs of the library,
+// a track without isrc can not be matched and is reported missing
+func missingRelease(ctx context.Context, follow models.Follow, kind models.ReleaseType, release models.ArtistDataAlbum) (models.MissingRelease, error) {
+	album, err := plugins.GetAlbum(ctx, follow.UserId, follow.Provider, release.Id)
+	if err != nil {
+		return models.MissingRelease{}, err
+	}
+
+	isrcs := make([]string, 0, len(album.Songs))
+	for _, song := range album.Songs {
+		if song.Isrc != "" {
+			isrcs = append(isrcs, song.Isrc)
+		}
+	}
+	owned, err := repository.ListOwnedISRCsByUserID(follow.UserId, isrcs)
+	if err != nil {
+		return models.MissingRelease{}, err
+	}
+
+	item := models.MissingRelease{
+		FollowId:    follow.ID,
+		ArtistName:  follow.ArtistName,
+		Provider:    follow.Provider,
+		Id:          release.Id,
+		Type:        kind,
+		Title:       release.Title,
+		ReleaseDate: release.ReleaseDate,
+		CoverUrl:    release.CoverUrl,
+		Total:       len(album.Songs),
+		Missing:     make([]models.MissingTrack, 0),
+	}
+	for _, song := range album.Songs {
+		if song.Isrc != "" && slices.Contains(owned, song.Isrc) {
+			item.Owned++
+			continue
+		}
+		item.Missing = append(item.Missing, models.MissingTrack{
+			Id:           song.Id,
+			Title:        song.Title,
+			TrackNumber:  song.TrackNumber,
+			VolumeNumber: song.VolumeNumber,
+			Isrc:         song.Isrc,
+		})
+	}
+
+	switch {
+	case len(item.Missing) == 0:
+		item.Status = models.MissingComplete
+	case item.Owned == 0:
+		item.Status = models.MissingAll
+	default:
+		item.Status = models.MissingPartial
+	}
+	return item, nil
+}
+
+// MissingDiscography classifies every album, ep and single of the followed artists against the library
+func MissingDiscography(ctx context.Context, follows []models.Follow) models.ResponseMissing {
+	result := models.ResponseMissing{
+		Items:  make([]models.MissingRelease, 0),
+		Errors: make([]string, 0),
+	}
+
+	for _, follow := range follows {
+		artist, err := plugins.GetArtist(ctx, follow.UserId, follow.Provider, follow.ArtistId)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", follow.ArtistName, err))
+			continue
+		}
+
+		for _, group := range []struct {
+			kind     models.ReleaseType
+			releases []models.ArtistDataAlbum
+		}{
+			{models.ReleaseAlbum, artist.Albums},
+			{models.ReleaseEp, artist.Ep},
+			{models.ReleaseSingle, artist.Singles},
+		} {
+			for _, release := range group.releases {
+				item, err := missingRelease(ctx, follow, group.kind, release)
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: %s: %s", follow.ArtistName, release.Title, err))
+					continue
+				}
+				switch item.Status {
+				case models.MissingComplete:
+					result.Complete++
+				case models.MissingPartial:
+					result.Partial++
+				case models.MissingAll:
+					result.Missing++
+				}
+				result.Items = append(result.Items, item)
+			}
+		}
+	}
+	return result
+}
+
+// QueueMissing queues the whole release when nothing is owned and only the missing tracks otherwise
+func QueueMissing(userId uint, report models.ResponseMissing) models.ResponseMissingQueue {
+	var queued models.ResponseMissingQueue
+	for _, item := range report.Items {
+		switch item.Status {
+		case models.MissingAll:
+			DownloadManager.AddAlbum(userId, item.Provider, item.Id, models.PriorityManual)
+			queued.Albums++
+		case models.MissingPartial:
+			for _, track := range item.Missing {
+				DownloadManager.AddSong(userId, item.Provider, track.Id, models.PriorityManual)
+				queued.Songs++
+			}
+		}
+	}
+	return queued
+}
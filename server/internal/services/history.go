@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrHistoryCreation = errors.New("the creation of a song cannot be reverted")
+
+func diffTags(oldTags map[string][]string, tags map[string][]string) []models.TagChange {
+	keys := slices.Sorted(maps.Keys(tags))
+	for key := range oldTags {
+		if _, ok := tags[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	changes := make([]models.TagChange, 0)
+	for _, key := range keys {
+		if !slices.Equal(oldTags[key], tags[key]) {
+			changes = append(changes, models.TagChange{Tag: key, Old: oldTags[key], New: tags[key]})
+		}
+	}
+	return changes
+}
+
+// RecordSongHistory saves the difference between two states of a song, nothing is saved when neither
+// the tags nor the path changed
+func RecordSongHistory(userId uint, songId uint, actor models.HistoryActor, oldPath string, path string, oldTags map[string][]string, tags map[string][]string) error {
+	changes := diffTags(oldTags, tags)
+	if len(changes) == 0 && oldPath == path {
+		return nil
+	}
+	if err := repository.AddSongHistory(&models.SongHistory{
+		UserId:  userId,
+		SongId:  songId,
+		Actor:   actor,
+		OldPath: oldPath,
+		Path:    path,
+		Changes: changes,
+		Tags:    tags,
+	}); err != nil {
+		return fmt.Errorf("services.RecordSongHistory: %w", err)
+	}
+	return nil
+}
+
+// recordSyncHistory diffs a file changed outside of the app against the last recorded state, a song
+// without history only gets its current tags saved as a starting point
+func recordSyncHistory(userId uint, songId uint, path string, tags map[string][]string) error {
+	last, err := repository.GetLastSongHistory(songId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := repository.AddSongHistory(&models.SongHistory{
+			UserId:  userId,
+			SongId:  songId,
+			Actor:   models.HistorySync,
+			OldPath: path,
+			Path:    path,
+			Changes: []models.TagChange{},
+			Tags:    tags,
+		}); err != nil {
+			return fmt.Errorf("recordSyncHistory: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("recordSyncHistory: %w", err)
+	}
+	if err := RecordSongHistory(userId, songId, models.HistorySync, last.Path, path, last.Tags, tags); err != nil {
+		return fmt.Errorf("recordSyncHistory: %w", err)
+	}
+	return nil
+}
+
+func ListSongHistory(userId uint, id uint) ([]models.SongHistory, error) {
+	if _, err := repository.GetSongByUserID(userId, id); err != nil {
+		return nil, fmt.Errorf("services.ListSongHistory: %w", err)
+	}
+	history, err := repository.ListSongHistoryByUserID(userId, id)
+	if err != nil {
+		return nil, fmt.Errorf("services.ListSongHistory: %w", err)
+	}
+	return history, nil
+}
+
+// RevertSongHistory undoes the revision rev and every later one: each tag they changed gets back the
+// value it had before rev, and the song moves with its tags like any other edit
+func RevertSongHistory(userId uint, id uint, rev uint) (models.BulkEditSong, error) {
+	history, err := repository.ListSongHistoryFromRevByUserID(userId, id, rev)
+	if err != nil {
+		return models.BulkEditSong{}, fmt.Errorf("services.RevertSongHistory: %w", err)
+	}
+	if len(history) == 0 || history[len(history)-1].Rev != rev {
+		return models.BulkEditSong{}, fmt.Errorf("services.RevertSongHistory: rev %d: %w", rev, gorm.ErrRecordNotFound)
+	}
+	if history[len(history)-1].OldPath == "" {
+		return models.BulkEditSong{}, fmt.Errorf("services.RevertSongHistory: %w", ErrHistoryCreation)
+	}
+
+	patch := make(map[string][]string)
+	for _, entry := range history {
+		for _, change := range entry.Changes {
+			patch[change.Tag] = change.Old
+		}
+	}
+	operations := make([]models.RequestBulkOperation, 0, len(patch))
+	for _, tag := range slices.Sorted(maps.Keys(patch)) {
+		if len(patch[tag]) == 0 {
+			operations = append(operations, models.RequestBulkOperation{Op: models.BulkClear, Tag: tag})
+		} else {
+			operations = append(operations, models.RequestBulkOperation{Op: models.BulkSet, Tag: tag, Values: patch[tag]})
+		}
+	}
+
+	result, err := bulkEdit(userId, models.RequestBulkEdit{Ids: []uint{id}, Operations: operations}, models.HistoryRevert)
+	if err != nil {
+		return models.BulkEditSong{}, fmt.Errorf("services.RevertSongHistory: %w", err)
+	}
+	return result.Songs[0], nil
+}
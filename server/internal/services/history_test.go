@@ -0,0 +1,70 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+func TestDiffTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldTags map[string][]string
+		tags    map[string][]string
+		want    []models.TagChange
+	}{
+		{
+			name:    "same tags",
+			oldTags: map[string][]string{"TITLE": {"Song"}, "ARTISTS": {"Band", "Other"}},
+			tags:    map[string][]string{"TITLE": {"Song"}, "ARTISTS": {"Band", "Other"}},
+			want:    []models.TagChange{},
+		},
+		{
+			name:    "both empty",
+			oldTags: nil,
+			tags:    map[string][]string{},
+			want:    []models.TagChange{},
+		},
+		{
+			name:    "changed value",
+			oldTags: map[string][]string{"TITLE": {"Song"}},
+			tags:    map[string][]string{"TITLE": {"Song (Live)"}},
+			want:    []models.TagChange{{Tag: "TITLE", Old: []string{"Song"}, New: []string{"Song (Live)"}}},
+		},
+		{
+			name:    "order of a list",
+			oldTags: map[string][]string{"ARTISTS": {"Band", "Other"}},
+			tags:    map[string][]string{"ARTISTS": {"Other", "Band"}},
+			want:    []models.TagChange{{Tag: "ARTISTS", Old: []string{"Band", "Other"}, New: []string{"Other", "Band"}}},
+		},
+		{
+			name:    "added and removed keys sorted",
+			oldTags: map[string][]string{"TITLE": {"Song"}, "GENRE": {"Rock"}},
+			tags:    map[string][]string{"TITLE": {"Song"}, "ALBUM": {"Record"}},
+			want: []models.TagChange{
+				{Tag: "ALBUM", Old: nil, New: []string{"Record"}},
+				{Tag: "GENRE", Old: []string{"Rock"}, New: nil},
+			},
+		},
+		{
+			name:    "empty value equals a missing key",
+			oldTags: map[string][]string{"GENRE": {}},
+			tags:    map[string][]string{},
+			want:    []models.TagChange{},
+		},
+		{
+			name:    "creation",
+			oldTags: nil,
+			tags:    map[string][]string{"TITLE": {"Song"}},
+			want:    []models.TagChange{{Tag: "TITLE", Old: nil, New: []string{"Song"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffTags(tt.oldTags, tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffTags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -51,9 +51,23 @@ func GetSongPathByTags(tags map[string][]string, extension string) (string, erro
 }
 
 func GetLibrarySong(info models.Song) (models.ResponseSong, error) {
+	userPath, err := utils.GetUserPath(info.UserId)
+	if err != nil {
+		return models.ResponseSong{}, fmt.Errorf("services.GetLibrarySong: %w", err)
+	}
+	song, err := readLibrarySong(userPath, info)
+	if err != nil {
+		return models.ResponseSong{}, fmt.Errorf("services.GetLibrarySong: %w", err)
+	}
+	return song, nil
+}
+
+// readLibrarySong reads the tags of a song of the user folder userPath
+func readLibrarySong(userPath string, info models.Song) (models.ResponseSong, error) {
 	song := models.ResponseSong{
 		ID:           info.ID,
 		Isrc:         info.Isrc,
+		AudioQuality: info.AudioQuality,
 		Title:        "",
 		Album:        "",
 		Artists:      []string{},
@@ -67,24 +81,23 @@ func GetLibrarySong(info models.Song) (models.ResponseSong, error) {
 		AlbumPeak:    0,
 		TrackGain:    0,
 		TrackPeak:    0,
+		Genres:       []string{},
+		Composers:    []string{},
+		Lyricists:    []string{},
 	}
 
-	path, err := utils.GetUserPath(info.UserId)
-	if err != nil {
-		return models.ResponseSong{}, fmt.Errorf("services.GetLibrarySong: %w", err)
-	}
-	path = filepath.Join(path, info.Path)
+	path := filepath.Join(userPath, info.Path)
 
 	properties, err := taglib.ReadProperties(path)
 	if err != nil {
-		return models.ResponseSong{}, fmt.Errorf("services.GetLibrarySong: %w", err)
+		return models.ResponseSong{}, fmt.Errorf("readLibrarySong: %w", err)
 	}
 
 	song.Duration = uint(properties.Length.Seconds())
 
-	tags, err := taglib.ReadTags(path)
+	tags, err := metadata.ReadTags(path)
 	if err != nil {
-		return models.ResponseSong{}, fmt.Errorf("services.GetLibrarySong: %w", err)
+		return models.ResponseSong{}, fmt.Errorf("readLibrarySong: %w", err)
 	}
 
 	if title, ok := tags[models.TagTitle]; ok && len(title) > 0 {
@@ -108,9 +121,7 @@ func GetLibrarySong(info models.Song) (models.ResponseSong, error) {
 	}
 
 	if explicit, ok := tags[models.TagExplicit]; ok && len(explicit) > 0 {
-		if explicit, err := strconv.ParseBool(explicit[0]); err == nil {
-			song.Explicit = explicit
-		}
+		song.Explicit = metadata.ParseExplicit(explicit[0])
 	}
 
 	if album, ok := tags[models.TagAlbum]; ok && len(album) > 0 {
@@ -149,6 +160,53 @@ func GetLibrarySong(info models.Song) (models.ResponseSong, error) {
 		}
 	}
 
+	if genres, ok := tags[models.TagGenre]; ok && len(genres) > 0 {
+		song.Genres = genres
+	}
+
+	if label, ok := tags[models.TagLabel]; ok && len(label) > 0 {
+		song.Label = label[0]
+	}
+
+	if copyright, ok := tags[models.TagCopyright]; ok && len(copyright) > 0 {
+		song.Copyright = copyright[0]
+	}
+
+	if barcode, ok := tags[models.TagBarcode]; ok && len(barcode) > 0 {
+		song.Barcode = barcode[0]
+	}
+
+	if composers, ok := tags[models.TagComposer]; ok && len(composers) > 0 {
+		song.Composers = composers
+	}
+
+	if lyricists, ok := tags[models.TagLyricist]; ok && len(lyricists) > 0 {
+		song.Lyricists = lyricists
+	}
+
+	if bpm, ok := tags[models.TagBPM]; ok && len(bpm) > 0 {
+		// some taggers write a decimal bpm
+		if bpm, err := strconv.ParseFloat(bpm[0], 64); err == nil && bpm > 0 {
+			song.Bpm = uint(bpm + 0.5)
+		}
+	}
+
+	if key, ok := tags[models.TagKey]; ok && len(key) > 0 {
+		song.Key = key[0]
+	}
+
+	if trackTotal, ok := tags[models.TagTrackTotal]; ok && len(trackTotal) > 0 {
+		if trackTotal, err := strconv.ParseUint(trackTotal[0], 10, 0); err == nil {
+			song.TrackTotal = uint(trackTotal)
+		}
+	}
+
+	if discTotal, ok := tags[models.TagDiscTotal]; ok && len(discTotal) > 0 {
+		if discTotal, err := strconv.ParseUint(discTotal[0], 10, 0); err == nil {
+			song.DiscTotal = uint(discTotal)
+		}
+	}
+
 	return song, nil
 }
 
@@ -173,46 +231,29 @@ func GetLibrarySongCover(userId uint, id uint) ([]byte, error) {
 	return img, nil
 }
 
-func DeleteLibrarySong(userId uint, id uint) error {
-	song, err := repository.GetSongByUserID(userId, id)
-	if err != nil {
-		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
-	}
-
-	userPath, err := utils.GetUserPath(userId)
-	if err != nil {
-		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
-	}
-
-	path := filepath.Join(userPath, song.Path)
-	if err := os.Remove(path); err != nil {
-		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
-	}
-
-	dir := filepath.Dir(path)
+// pruneEmptyDirs removes dir and its parents up to the user folder while they hold nothing but art
+func pruneEmptyDirs(userPath string, dir string) {
 	root, _ := filepath.Abs(userPath)
 	for {
 		dirAbs, _ := filepath.Abs(dir)
 		if dirAbs == root || dirAbs == "/" {
 			break
 		}
+		metadata.RemoveArtOnlyDir(dirAbs)
 		if err := os.Remove(dirAbs); err != nil {
 			break
 		}
 		dir = filepath.Dir(dir)
 	}
-
-	if err := repository.DeleteSongByUserID(userId, id); err != nil {
-		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
-	}
-	return nil
 }
 
 func SyncUserLibrary(userId uint) error {
 	type songItem struct {
-		id    uint
-		isrc  string
-		mtime time.Time
+		id      uint
+		isrc    string
+		quality models.QualityLevel
+		mtime   time.Time
+		tags    map[string][]string
 	}
 	addList := make(map[string]songItem)
 	updateList := make(map[string]songItem)
@@ -230,6 +271,9 @@ func SyncUserLibrary(userId uint) error {
 		if d.IsDir() {
 			return nil
 		}
+		if utils.IsTemp(d.Name()) || isPlaylistFile(d.Name()) || metadata.IsArtFile(d.Name()) {
+			return nil
+		}
 
 		fileinfo, err := os.Stat(path)
 		if err != nil {
@@ -255,13 +299,21 @@ func SyncUserLibrary(userId uint) error {
 			return nil
 		}
 
+		quality, err := metadata.ReadQuality(path)
+		if err != nil {
+			log.Println("services.SyncUserLibrary: filepath.WalkDir:", path, err)
+		}
+
 		addList[relPath] = songItem{
-			isrc:  isrc,
-			mtime: fileinfo.ModTime().UTC(),
+			isrc:    isrc,
+			quality: quality,
+			mtime:   fileinfo.ModTime().UTC(),
 		}
 		diskList[relPath] = songItem{
-			isrc:  isrc,
-			mtime: fileinfo.ModTime().UTC(),
+			isrc:    isrc,
+			quality: quality,
+			mtime:   fileinfo.ModTime().UTC(),
+			tags:    tags,
 		}
 		return nil
 	}); err != nil {
@@ -269,15 +321,16 @@ func SyncUserLibrary(userId uint) error {
 	}
 
 	dbList := make(map[string]songItem)
-	tmpDbList, err := repository.ListSongByUserID(userId, "", -1, 0)
+	tmpDbList, err := repository.ListSongByUserID(userId, "", "", -1, 0)
 	if err != nil {
 		return fmt.Errorf("services.SyncUserLibrary: %w", err)
 	}
 	for _, item := range tmpDbList {
 		dbList[item.Path] = songItem{
-			id:    item.ID,
-			isrc:  item.Isrc,
-			mtime: item.MTime,
+			id:      item.ID,
+			isrc:    item.Isrc,
+			quality: item.AudioQuality,
+			mtime:   item.MTime,
 		}
 		deleteList[item.Path] = songItem{
 			id:    item.ID,
@@ -290,11 +343,18 @@ func SyncUserLibrary(userId uint) error {
 		if diskItem, ok := diskList[path]; ok {
 			delete(addList, path)
 			delete(deleteList, path)
-			if !diskItem.mtime.Equal(dbItem.mtime) || diskItem.isrc != dbItem.isrc {
+			if !diskItem.mtime.Equal(dbItem.mtime) || diskItem.isrc != dbItem.isrc || dbItem.quality == "" {
+				// the quality recorded at download time is exact, the estimation only fills the gaps
+				quality := dbItem.quality
+				if quality == "" {
+					quality = diskItem.quality
+				}
 				updateList[path] = songItem{
-					id:    dbItem.id,
-					isrc:  diskItem.isrc,
-					mtime: diskItem.mtime,
+					id:      dbItem.id,
+					isrc:    diskItem.isrc,
+					quality: quality,
+					mtime:   diskItem.mtime,
+					tags:    diskItem.tags,
 				}
 			}
 		}
@@ -306,12 +366,16 @@ func SyncUserLibrary(userId uint) error {
 		}
 	}
 	for path, item := range updateList {
-		if err := repository.UpdateSongByUserID(userId, models.Song{ID: item.id, Path: path, Isrc: item.isrc, MTime: item.mtime}); err != nil {
+		if err := repository.UpdateSongByUserID(userId, models.Song{ID: item.id, Path: path, Isrc: item.isrc, AudioQuality: item.quality, MTime: item.mtime}); err != nil {
+			log.Println("services.SyncUserLibrary:", err)
+			continue
+		}
+		if err := recordSyncHistory(userId, item.id, path, item.tags); err != nil {
 			log.Println("services.SyncUserLibrary:", err)
 		}
 	}
 	for path, item := range addList {
-		if err := repository.AddSong(models.Song{UserId: userId, Path: path, Isrc: item.isrc, MTime: item.mtime}); err != nil {
+		if err := repository.AddSong(&models.Song{UserId: userId, Path: path, Isrc: item.isrc, AudioQuality: item.quality, MTime: item.mtime}); err != nil {
 			log.Println("services.SyncUserLibrary:", err)
 		}
 	}
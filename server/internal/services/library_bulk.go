@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/metadata"
+	"gorm.io/gorm"
+)
+
+var ErrBulkConflict = errors.New("bulk edit conflict")
+
+type bulkSong struct {
+	song    models.Song
+	oldTags map[string][]string
+	tags    map[string][]string
+	written map[string][]string
+	tmpPath string
+	bakPath string
+	path    string
+	moved   bool
+}
+
+func validateBulkOperations(operations []models.RequestBulkOperation) ([]models.RequestBulkOperation, error) {
+	if len(operations) == 0 {
+		return nil, errors.New("no operation")
+	}
+	valid := make([]models.RequestBulkOperation, 0, len(operations))
+	for _, op := range operations {
+		op.Tag = strings.ToUpper(strings.TrimSpace(op.Tag))
+		switch op.Op {
+		case models.BulkSet:
+			if op.Tag == "" || len(op.Values) == 0 {
+				return nil, errors.New("set needs a tag and values")
+			}
+		case models.BulkClear:
+			if op.Tag == "" {
+				return nil, errors.New("clear needs a tag")
+			}
+		case models.BulkReplace:
+			if op.Tag == "" || op.Find == "" {
+				return nil, errors.New("replace needs a tag and a text to find")
+			}
+		case models.BulkRenumber:
+			if op.Start == 0 {
+				op.Start = 1
+			}
+		default:
+			return nil, fmt.Errorf("invalid operation %q", op.Op)
+		}
+		valid = append(valid, op)
+	}
+	return valid, nil
+}
+
+func tagNumber(tags map[string][]string, key string) uint64 {
+	if values, ok := tags[key]; ok && len(values) > 0 {
+		if number, err := strconv.ParseUint(strings.SplitN(values[0], "/", 2)[0], 10, 0); err == nil {
+			return number
+		}
+	}
+	return 0
+}
+
+// bulkAlbumSongs finds the songs of an album by their album and album artist tags, the paths are
+// searched by the album title first so only its candidates are read
+func bulkAlbumSongs(userId uint, userPath string, album models.RequestBulkAlbum) ([]models.Song, error) {
+	if strings.TrimSpace(album.Album) == "" {
+		return nil, errors.New("album title is empty")
+	}
+	candidates, err := repository.SearchSongByUserID(userId, []string{strings.ReplaceAll(album.Album, "/", "_")}, -1)
+	if err != nil {
+		return nil, err
+	}
+	songs := make([]models.Song, 0)
+	for _, song := range candidates {
+		tags, err := metadata.ReadTags(filepath.Join(userPath, song.Path))
+		if err != nil {
+			log.Println("bulkAlbumSongs:", err)
+			continue
+		}
+		if title, ok := tags[models.TagAlbum]; !ok || len(title) == 0 || !strings.EqualFold(title[0], album.Album) {
+			continue
+		}
+		if album.AlbumArtist != "" && !slices.ContainsFunc(tags[models.TagAlbumArtists], func(name string) bool {
+			return strings.EqualFold(name, album.AlbumArtist)
+		}) {
+			continue
+		}
+		songs = append(songs, song)
+	}
+	return songs, nil
+}
+
+func applyBulkOperations(items []*bulkSong, operations []models.RequestBulkOperation) {
+	for _, op := range operations {
+		switch op.Op {
+		case models.BulkSet:
+			for _, item := range items {
+				item.tags[op.Tag] = slices.Clone(op.Values)
+			}
+		case models.BulkClear:
+			for _, item := range items {
+				item.tags[op.Tag] = []string{}
+			}
+		case models.BulkReplace:
+			for _, item := range items {
+				values, ok := item.tags[op.Tag]
+				if !ok {
+					continue
+				}
+				replaced := make([]string, 0, len(values))
+				for _, value := range values {
+					replaced = append(replaced, strings.ReplaceAll(value, op.Find, op.Replace))
+				}
+				item.tags[op.Tag] = replaced
+			}
+		case models.BulkRenumber:
+			totals := make(map[uint64]uint)
+			for _, item := range items {
+				disc := uint64(0)
+				if op.PerDisc {
+					disc = tagNumber(item.tags, models.TagVolumeNumber)
+				}
+				totals[disc]++
+			}
+			counters := make(map[uint64]uint)
+			for _, item := range items {
+				disc := uint64(0)
+				if op.PerDisc {
+					disc = tagNumber(item.tags, models.TagVolumeNumber)
+				}
+				item.tags[models.TagTrackNumber] = []string{strconv.FormatUint(uint64(op.Start+counters[disc]), 10)}
+				item.tags[models.TagTrackTotal] = []string{strconv.FormatUint(uint64(totals[disc]), 10)}
+				counters[disc]++
+			}
+		}
+	}
+}
+
+// changedTags keeps the keys whose values changed, the untouched keys stay as the file stores them
+func changedTags(oldTags map[string][]string, tags map[string][]string) map[string][]string {
+	changed := make(map[string][]string)
+	for key, values := range tags {
+		if !slices.Equal(oldTags[key], values) {
+			changed[key] = values
+		}
+	}
+	return changed
+}
+
+func copyNextTo(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := utils.CreateTemp(filepath.Dir(path), "bulk-*"+filepath.Ext(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// restoreBulk puts the original files back after a failed move
+func restoreBulk(userPath string, items []*bulkSong) {
+	for _, item := range items {
+		if item.moved {
+			if err := os.Remove(filepath.Join(userPath, item.path)); err != nil {
+				log.Println("restoreBulk:", err)
+			}
+		}
+		if item.bakPath != "" {
+			if err := os.Rename(item.bakPath, filepath.Join(userPath, item.song.Path)); err != nil {
+				log.Println("restoreBulk:", err)
+			}
+		}
+	}
+}
+
+// BulkEditLibrary applies a tag patch to several songs at once. Every file is tagged on a copy first,
+// then the database paths and the files are switched in a single transaction, so either every song
+// changes or none does
+func BulkEditLibrary(userId uint, req models.RequestBulkEdit) (models.ResponseBulkEdit, error) {
+	return bulkEdit(userId, req, models.HistoryBulk)
+}
+
+func bulkEdit(userId uint, req models.RequestBulkEdit, actor models.HistoryActor) (models.ResponseBulkEdit, error) {
+	operations, err := validateBulkOperations(req.Operations)
+	if err != nil {
+		return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+	}
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+	}
+
+	var songs []models.Song
+	switch {
+	case len(req.Ids) > 0:
+		found, err := repository.ListSongsByUserIDByIDs(userId, req.Ids)
+		if err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+		}
+		byId := make(map[uint]models.Song, len(found))
+		for _, song := range found {
+			byId[song.ID] = song
+		}
+		for _, id := range req.Ids {
+			song, ok := byId[id]
+			if !ok {
+				return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: song %d: %w", id, gorm.ErrRecordNotFound)
+			}
+			if !slices.ContainsFunc(songs, func(s models.Song) bool { return s.ID == id }) {
+				songs = append(songs, song)
+			}
+		}
+	case req.Album != nil:
+		if songs, err = bulkAlbumSongs(userId, userPath, *req.Album); err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+		}
+		if len(songs) == 0 {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: album: %w", gorm.ErrRecordNotFound)
+		}
+	default:
+		return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", errors.New("no song ids nor album given"))
+	}
+
+	items := make([]*bulkSong, 0, len(songs))
+	defer func() {
+		for _, item := range items {
+			if item.tmpPath != "" {
+				_ = os.Remove(item.tmpPath)
+			}
+		}
+	}()
+	for _, song := range songs {
+		tags, err := metadata.ReadTags(filepath.Join(userPath, song.Path))
+		if err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %s: %w", song.Path, err)
+		}
+		items = append(items, &bulkSong{song: song, oldTags: tags, tags: cloneTags(tags)})
+	}
+	// an album is renumbered in its disc and track order, a list of ids in the given order
+	if len(req.Ids) == 0 {
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := items[i], items[j]
+			if da, db := tagNumber(a.oldTags, models.TagVolumeNumber), tagNumber(b.oldTags, models.TagVolumeNumber); da != db {
+				return da < db
+			}
+			if ta, tb := tagNumber(a.oldTags, models.TagTrackNumber), tagNumber(b.oldTags, models.TagTrackNumber); ta != tb {
+				return ta < tb
+			}
+			return a.song.Path < b.song.Path
+		})
+	}
+	applyBulkOperations(items, operations)
+
+	profile := metadata.GetUserProfile(userId)
+	originals := make(map[string]bool, len(items))
+	for _, item := range items {
+		originals[item.song.Path] = true
+	}
+	targets := make(map[string]bool, len(items))
+	for _, item := range items {
+		src := filepath.Join(userPath, item.song.Path)
+		if item.tmpPath, err = copyNextTo(src); err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %s: %w", item.song.Path, err)
+		}
+		if err := metadata.WriteTags(item.tmpPath, metadata.ApplyProfile(profile, changedTags(item.oldTags, item.tags)), false); err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %s: %w", item.song.Path, err)
+		}
+		if item.written, err = metadata.ReadTags(item.tmpPath); err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %s: %w", item.song.Path, err)
+		}
+		if item.path, err = GetSongPathByTags(item.written, filepath.Ext(item.song.Path)); err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %s: %w", item.song.Path, err)
+		}
+
+		if targets[item.path] {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w: two songs would move to %s", ErrBulkConflict, item.path)
+		}
+		targets[item.path] = true
+		if item.path != item.song.Path {
+			if originals[item.path] {
+				return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w: %s is taken by another edited song", ErrBulkConflict, item.path)
+			}
+			if _, err := os.Stat(filepath.Join(userPath, item.path)); err == nil {
+				return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w: %s already exists", ErrBulkConflict, item.path)
+			}
+		}
+	}
+
+	updates := make([]models.Song, 0, len(items))
+	for _, item := range items {
+		info, err := os.Stat(item.tmpPath)
+		if err != nil {
+			return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+		}
+		updates = append(updates, models.Song{ID: item.song.ID, Path: item.path, MTime: info.ModTime().UTC()})
+	}
+
+	applied := false
+	if err := repository.UpdateSongPathsByUserID(userId, updates, func() error {
+		for _, item := range items {
+			src := filepath.Join(userPath, item.song.Path)
+			bak, err := utils.CreateTemp(filepath.Dir(src), "bulk-bak-*")
+			if err != nil {
+				return err
+			}
+			_ = bak.Close()
+			if err := os.Rename(src, bak.Name()); err != nil {
+				_ = os.Remove(bak.Name())
+				return err
+			}
+			item.bakPath = bak.Name()
+
+			dst := filepath.Join(userPath, item.path)
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := utils.RenameSoft(item.tmpPath, dst); err != nil {
+				return err
+			}
+			item.tmpPath = ""
+			item.moved = true
+		}
+		applied = true
+		return nil
+	}); err != nil {
+		restoreBulk(userPath, items)
+		if applied {
+			log.Println("services.BulkEditLibrary: commit failed after moving the files, they were restored")
+		}
+		return models.ResponseBulkEdit{}, fmt.Errorf("services.BulkEditLibrary: %w", err)
+	}
+
+	result := models.ResponseBulkEdit{Songs: make([]models.BulkEditSong, 0, len(items))}
+	for _, item := range items {
+		if err := os.Remove(item.bakPath); err != nil {
+			log.Println("services.BulkEditLibrary:", err)
+		}
+		if err := RecordSongHistory(userId, item.song.ID, actor, item.song.Path, item.path, item.oldTags, item.written); err != nil {
+			log.Println("services.BulkEditLibrary:", err)
+		}
+		if item.path != item.song.Path {
+			RemoveThumbnails(userId, item.song.Path)
+			pruneEmptyDirs(userPath, filepath.Dir(filepath.Join(userPath, item.song.Path)))
+			RefreshSongPlaylists(userId, item.song.ID)
+		}
+		result.Songs = append(result.Songs, models.BulkEditSong{Id: item.song.ID, OldPath: item.song.Path, Path: item.path})
+	}
+	return result, nil
+}
+
+func cloneTags(tags map[string][]string) map[string][]string {
+	clone := make(map[string][]string, len(tags))
+	for key, values := range tags {
+		clone[key] = slices.Clone(values)
+	}
+	return clone
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/metadata"
+	"go.senan.xyz/taglib"
+)
+
+var upgradeScans sync.Map
+
+func IsLibraryUpgradeScanning(userId uint) bool {
+	_, ok := upgradeScans.Load(userId)
+	return ok
+}
+
+func StartLibraryUpgradeScan(userId uint) error {
+	if _, running := upgradeScans.LoadOrStore(userId, struct{}{}); running {
+		return fmt.Errorf("services.StartLibraryUpgradeScan: %w", errors.New("upgrade scan already running"))
+	}
+
+	go func() {
+		defer upgradeScans.Delete(userId)
+		if err := ScanLibraryUpgrades(context.Background(), userId); err != nil {
+			log.Println(err)
+		}
+	}()
+	return nil
+}
+
+// findUpgrade searches every provider for the isrc and returns the best quality offered
+func findUpgrade(ctx context.Context, userId uint, isrc string, query string) (string, string, models.QualityLevel) {
+	var bestProvider, bestId string
+	var best models.QualityLevel
+	for provider, pluginsList := range plugins.GetAllPluginsByProvider() {
+		for _, plugin := range pluginsList {
+			found := false
+			for _, q := range []string{isrc, query} {
+				if q == "" {
+					continue
+				}
+				data, err := plugin.Search(ctx, userId, models.SearchQuery{Song: q})
+				if err != nil {
+					continue
+				}
+				for _, song := range data.Songs {
+					if !strings.EqualFold(song.Isrc, isrc) {
+						continue
+					}
+					found = true
+					if quality := models.QualityLevel(song.AudioQuality.Name); quality.Rank() > best.Rank() {
+						bestProvider, bestId, best = provider, song.Id, quality
+					}
+				}
+				if found {
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+	}
+	return bestProvider, bestId, best
+}
+
+// ScanLibraryUpgrades compares every song of the library with what the providers offer for the same isrc,
+// the bit depth is not exposed by taglib so hi-res is only told apart by the sample rate
+func ScanLibraryUpgrades(ctx context.Context, userId uint) error {
+	user, err := repository.GetUserByID(userId)
+	if err != nil {
+		return fmt.Errorf("services.ScanLibraryUpgrades: %w", err)
+	}
+	target := user.QualityPolicy().Best()
+
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("services.ScanLibraryUpgrades: %w", err)
+	}
+
+	songs, err := repository.ListSongByUserID(userId, "", "", -1, 0)
+	if err != nil {
+		return fmt.Errorf("services.ScanLibraryUpgrades: %w", err)
+	}
+
+	candidates := make([]models.UpgradeCandidate, 0)
+	for _, song := range songs {
+		if song.Isrc == "" {
+			continue
+		}
+		path := filepath.Join(userPath, song.Path)
+
+		properties, err := taglib.ReadProperties(path)
+		if err != nil {
+			log.Println("services.ScanLibraryUpgrades:", path, err)
+			continue
+		}
+
+		current := song.AudioQuality
+		if current == "" {
+			if current, err = metadata.ReadQuality(path); err != nil {
+				log.Println("services.ScanLibraryUpgrades:", path, err)
+				continue
+			}
+		}
+		if current.Rank() >= target.Rank() {
+			continue
+		}
+
+		var query string
+		if tags, err := metadata.ReadTags(path); err == nil {
+			if title, ok := tags[models.TagTitle]; ok && len(title) > 0 {
+				query = title[0]
+			}
+			if artists, ok := tags[models.TagArtists]; ok && len(artists) > 0 {
+				query = artists[0] + " " + query
+			}
+		}
+
+		provider, providerId, available := findUpgrade(ctx, userId, song.Isrc, query)
+		if available.Rank() > target.Rank() {
+			available = target
+		}
+		if available.Rank() <= current.Rank() {
+			continue
+		}
+
+		candidates = append(candidates, models.UpgradeCandidate{
+			UserId:     userId,
+			SongId:     song.ID,
+			Path:       song.Path,
+			Isrc:       song.Isrc,
+			Codec:      strings.TrimPrefix(strings.ToLower(filepath.Ext(song.Path)), "."),
+			SampleRate: uint(properties.SampleRate),
+			Bitrate:    uint(properties.Bitrate),
+			Current:    current,
+			Provider:   provider,
+			ProviderId: providerId,
+			Available:  available,
+			ScannedAt:  time.Now(),
+		})
+	}
+
+	if err := repository.ReplaceUpgradeCandidatesByUserID(userId, candidates); err != nil {
+		return fmt.Errorf("services.ScanLibraryUpgrades: %w", err)
+	}
+	return nil
+}
+
+// QueueLibraryUpgrades queues the replacement of the given candidates, every candidate when ids is empty
+func QueueLibraryUpgrades(userId uint, ids []uint) (int, error) {
+	var candidates []models.UpgradeCandidate
+	var err error
+	if len(ids) == 0 {
+		candidates, err = repository.ListUpgradeCandidatesByUserID(userId)
+	} else {
+		candidates, err = repository.ListUpgradeCandidatesByUserIDByIDs(userId, ids)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("services.QueueLibraryUpgrades: %w", err)
+	}
+
+	queued := 0
+	for _, candidate := range candidates {
+		song, err := repository.GetSongByUserID(userId, candidate.SongId)
+		if err != nil {
+			log.Println("services.QueueLibraryUpgrades:", err)
+			continue
+		}
+		song.AudioQuality = candidate.Current
+		DownloadManager.AddUpgrade(userId, candidate.Provider, candidate.ProviderId, song)
+		queued++
+	}
+	return queued, nil
+}
+
+// replaceSong swaps a library file with a better version, the tags and cover of the old file are
+// written over the provider ones so the user edits survive the upgrade
+func replaceSong(ctx context.Context, userId uint, reader io.ReadCloser, extension string, quality models.QualityLevel, data models.SongData, old models.Song) error {
+	defer reader.Close()
+
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("replaceSong: %w", err)
+	}
+	oldPath := filepath.Join(userPath, old.Path)
+
+	oldTags, err := metadata.ReadTags(oldPath)
+	if err != nil {
+		return fmt.Errorf("replaceSong: %w", err)
+	}
+	oldCover, _ := metadata.ReadCover(oldPath)
+
+	file, err := utils.CreateTemp(filepath.Dir(oldPath), "upgrade-*."+extension)
+	if err != nil {
+		return fmt.Errorf("replaceSong: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("replaceSong: io.Copy: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("replaceSong: file.Close: %w", err)
+	}
+
+	if err := metadata.FormatMetadata(ctx, userId, tmpPath, data); err != nil {
+		return fmt.Errorf("replaceSong: %w: %w", models.ErrTagging, err)
+	}
+	if err := metadata.WriteTags(tmpPath, metadata.ApplyProfile(metadata.GetUserProfile(userId), oldTags), false); err != nil {
+		return fmt.Errorf("replaceSong: %w: %w", models.ErrTagging, err)
+	}
+	if len(oldCover) > 0 {
+		if err := metadata.WriteCover(tmpPath, bytes.NewReader(oldCover)); err != nil {
+			return fmt.Errorf("replaceSong: %w: %w", models.ErrTagging, err)
+		}
+	}
+
+	newRelPath := strings.TrimSuffix(old.Path, filepath.Ext(old.Path)) + "." + extension
+	newPath := filepath.Join(userPath, newRelPath)
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return fmt.Errorf("replaceSong: %w", err)
+	}
+
+	// the old file is only dropped once the database points at the new one
+	var bakPath string
+	moved := false
+	if err := repository.ReplaceSongByUserID(userId, models.Song{ID: old.ID, Path: newRelPath, AudioQuality: quality, MTime: info.ModTime().UTC()}, func() error {
+		if newRelPath == old.Path {
+			bak, err := utils.CreateTemp(filepath.Dir(oldPath), "upgrade-bak-*")
+			if err != nil {
+				return err
+			}
+			_ = bak.Close()
+			if err := os.Rename(oldPath, bak.Name()); err != nil {
+				_ = os.Remove(bak.Name())
+				return err
+			}
+			bakPath = bak.Name()
+		}
+		if err := utils.RenameSoft(tmpPath, newPath); err != nil {
+			return err
+		}
+		moved = true
+		return nil
+	}); err != nil {
+		if moved {
+			if err := os.Remove(newPath); err != nil {
+				log.Println("replaceSong:", err)
+			}
+		}
+		if bakPath != "" {
+			if err := os.Rename(bakPath, oldPath); err != nil {
+				log.Println("replaceSong:", err)
+			}
+		}
+		return fmt.Errorf("replaceSong: %w", err)
+	}
+
+	if bakPath != "" {
+		oldPath = bakPath
+	}
+	if err := os.Remove(oldPath); err != nil {
+		log.Println("replaceSong:", err)
+	}
+	if newRelPath != old.Path {
+		RefreshSongPlaylists(userId, old.ID)
+	}
+
+	if err := repository.DeleteUpgradeCandidateBySongID(userId, old.ID); err != nil {
+		log.Println("replaceSong:", err)
+	}
+	return nil
+}
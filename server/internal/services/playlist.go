@@ -0,0 +1,404 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/metadata"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+)
+
+func responsePlaylist(p models.Playlist, count int64) models.ResponsePlaylist {
+	return models.ResponsePlaylist{
+		ID:        p.ID,
+		Name:      p.Name,
+		Path:      p.Path,
+		Count:     count,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func playlistEntries(items []models.PlaylistItem) []playlist.Entry {
+	entries := make([]playlist.Entry, 0, len(items))
+	for _, item := range items {
+		entry := playlist.Entry{Path: item.Song.Path, Isrc: item.Song.Isrc}
+		if song, err := GetLibrarySong(item.Song); err == nil {
+			entry.Title = song.Title
+			entry.Artist = strings.Join(song.Artists, ", ")
+			entry.Album = song.Album
+			entry.Duration = int(song.Duration)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// writePlaylist mirrors the playlist in its m3u8 file at the root of the library
+func writePlaylist(p models.Playlist) error {
+	items, err := repository.ListPlaylistItems(p.ID)
+	if err != nil {
+		return fmt.Errorf("writePlaylist: %w", err)
+	}
+	if err := writeM3U8(p.UserId, p.Path, p.Name, playlistEntries(items)); err != nil {
+		return fmt.Errorf("writePlaylist: %w", err)
+	}
+	return nil
+}
+
+// RefreshSongPlaylists rewrites the files of the playlists and subscriptions holding the song once its
+// path changed or it left or came back from the trash
+func RefreshSongPlaylists(userId uint, songId uint) {
+	playlists, err := repository.ListPlaylistsBySongID(songId)
+	if err != nil {
+		log.Println("services.RefreshSongPlaylists:", err)
+	}
+	for _, p := range playlists {
+		if err := writePlaylist(p); err != nil {
+			log.Println("services.RefreshSongPlaylists:", err)
+		}
+	}
+
+	subscriptions, err := repository.ListSubscriptionsByUserID(userId)
+	if err != nil {
+		log.Println("services.RefreshSongPlaylists:", err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if !slices.ContainsFunc(subscription.Songs, func(song models.SubscriptionSong) bool {
+			return song.SongId == songId
+		}) {
+			continue
+		}
+		if err := rewriteSubscriptionFile(subscription); err != nil {
+			log.Println("services.RefreshSongPlaylists:", err)
+		}
+	}
+}
+
+func CreatePlaylist(userId uint, name string) (models.ResponsePlaylist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.ResponsePlaylist{}, fmt.Errorf("services.CreatePlaylist: %w", errors.New("empty playlist name"))
+	}
+
+	path, err := newPlaylistPath(userId, name, ".m3u8")
+	if err != nil {
+		return models.ResponsePlaylist{}, fmt.Errorf("services.CreatePlaylist: %w", err)
+	}
+
+	p := models.Playlist{UserId: userId, Name: name, Path: path}
+	if err := repository.AddPlaylist(&p); err != nil {
+		return models.ResponsePlaylist{}, fmt.Errorf("services.CreatePlaylist: %w", err)
+	}
+	if err := writePlaylist(p); err != nil {
+		return models.ResponsePlaylist{}, fmt.Errorf("services.CreatePlaylist: %w", err)
+	}
+	return responsePlaylist(p, 0), nil
+}
+
+func GetPlaylist(userId uint, id uint) (models.ResponsePlaylistDetail, error) {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return models.ResponsePlaylistDetail{}, fmt.Errorf("services.GetPlaylist: %w", err)
+	}
+	items, err := repository.ListPlaylistItems(p.ID)
+	if err != nil {
+		return models.ResponsePlaylistDetail{}, fmt.Errorf("services.GetPlaylist: %w", err)
+	}
+
+	detail := models.ResponsePlaylistDetail{
+		ResponsePlaylist: responsePlaylist(p, int64(len(items))),
+		Items:            make([]models.ResponsePlaylistItem, 0, len(items)),
+	}
+	for _, item := range items {
+		song, err := GetLibrarySong(item.Song)
+		if err != nil {
+			log.Println("services.GetPlaylist:", err)
+			song = models.ResponseSong{ID: item.Song.ID, Isrc: item.Song.Isrc, Artists: []string{}, AlbumArtists: []string{}}
+		}
+		detail.Items = append(detail.Items, models.ResponsePlaylistItem{ID: item.ID, Position: item.Position, Song: song})
+	}
+	return detail, nil
+}
+
+// RenamePlaylist moves the playlist file along with the name
+func RenamePlaylist(userId uint, id uint, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("services.RenamePlaylist: %w", errors.New("empty playlist name"))
+	}
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.RenamePlaylist: %w", err)
+	}
+	if name == p.Name {
+		return nil
+	}
+
+	oldPath := p.Path
+	if p.Path, err = newPlaylistPath(userId, name, ".m3u8"); err != nil {
+		return fmt.Errorf("services.RenamePlaylist: %w", err)
+	}
+	p.Name = name
+	if err := repository.UpdatePlaylistName(p.ID, p.Name, p.Path); err != nil {
+		return fmt.Errorf("services.RenamePlaylist: %w", err)
+	}
+	if err := writePlaylist(p); err != nil {
+		return fmt.Errorf("services.RenamePlaylist: %w", err)
+	}
+	if err := removePlaylistFile(userId, oldPath); err != nil {
+		log.Println("services.RenamePlaylist:", err)
+	}
+	return nil
+}
+
+func AddPlaylistSongs(userId uint, id uint, songIds []uint) error {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.AddPlaylistSongs: %w", err)
+	}
+	for _, songId := range songIds {
+		if _, err := repository.GetSongByUserID(userId, songId); err != nil {
+			return fmt.Errorf("services.AddPlaylistSongs: %w", err)
+		}
+	}
+
+	if err := repository.AddPlaylistItems(p.ID, songIds); err != nil {
+		return fmt.Errorf("services.AddPlaylistSongs: %w", err)
+	}
+	if err := writePlaylist(p); err != nil {
+		return fmt.Errorf("services.AddPlaylistSongs: %w", err)
+	}
+	return nil
+}
+
+func RemovePlaylistItem(userId uint, id uint, itemId uint) error {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.RemovePlaylistItem: %w", err)
+	}
+	if err := repository.DeletePlaylistItem(p.ID, itemId); err != nil {
+		return fmt.Errorf("services.RemovePlaylistItem: %w", err)
+	}
+	if err := writePlaylist(p); err != nil {
+		return fmt.Errorf("services.RemovePlaylistItem: %w", err)
+	}
+	return nil
+}
+
+func ReorderPlaylist(userId uint, id uint, itemIds []uint) error {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.ReorderPlaylist: %w", err)
+	}
+	if err := repository.ReorderPlaylistItems(p.ID, itemIds); err != nil {
+		return fmt.Errorf("services.ReorderPlaylist: %w", err)
+	}
+	if err := writePlaylist(p); err != nil {
+		return fmt.Errorf("services.ReorderPlaylist: %w", err)
+	}
+	return nil
+}
+
+func DeletePlaylist(userId uint, id uint) error {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.DeletePlaylist: %w", err)
+	}
+	if err := removePlaylistFile(userId, p.Path); err != nil {
+		return fmt.Errorf("services.DeletePlaylist: %w", err)
+	}
+	if err := repository.DeletePlaylistByUserID(userId, id); err != nil {
+		return fmt.Errorf("services.DeletePlaylist: %w", err)
+	}
+	return nil
+}
+
+// ExportPlaylist returns the playlist in the given format with the file name to download it as
+func ExportPlaylist(userId uint, id uint, format playlist.Format) ([]byte, string, error) {
+	p, err := repository.GetPlaylistByUserID(userId, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("services.ExportPlaylist: %w", err)
+	}
+	items, err := repository.ListPlaylistItems(p.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("services.ExportPlaylist: %w", err)
+	}
+
+	var content bytes.Buffer
+	if err := playlist.Write(format, &content, p.Name, playlistEntries(items)); err != nil {
+		return nil, "", fmt.Errorf("services.ExportPlaylist: %w", err)
+	}
+	return content.Bytes(), strings.TrimSuffix(p.Path, filepath.Ext(p.Path)) + "." + string(format), nil
+}
+
+// libraryMatcher finds the library song of a playlist entry by path, then isrc, then artist and title,
+// the tags of the library are only read once an entry needs the artist and title
+type libraryMatcher struct {
+	userPath string
+	songs    []models.Song
+	byPath   map[string]uint
+	byIsrc   map[string]uint
+	byTitle  map[string]uint
+}
+
+func newLibraryMatcher(userId uint) (*libraryMatcher, error) {
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return nil, fmt.Errorf("newLibraryMatcher: %w", err)
+	}
+	songs, err := repository.ListSongByUserID(userId, "", "", -1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("newLibraryMatcher: %w", err)
+	}
+
+	m := &libraryMatcher{
+		userPath: userPath,
+		songs:    songs,
+		byPath:   make(map[string]uint, len(songs)),
+		byIsrc:   make(map[string]uint, len(songs)),
+	}
+	for _, song := range songs {
+		m.byPath[filepath.ToSlash(song.Path)] = song.ID
+		if song.Isrc != "" {
+			m.byIsrc[strings.ToUpper(song.Isrc)] = song.ID
+		}
+	}
+	return m, nil
+}
+
+func titleKey(artist string, title string) string {
+	return strings.ToLower(strings.TrimSpace(artist)) + "\x00" + strings.ToLower(strings.TrimSpace(title))
+}
+
+func (m *libraryMatcher) loadTitles() {
+	m.byTitle = make(map[string]uint, len(m.songs))
+	for _, song := range m.songs {
+		tags, err := metadata.ReadTags(filepath.Join(m.userPath, song.Path))
+		if err != nil {
+			continue
+		}
+		title, ok := tags[models.TagTitle]
+		if !ok || len(title) == 0 {
+			continue
+		}
+		for _, artist := range append(tags[models.TagArtists], tags[models.TagAlbumArtists]...) {
+			if _, ok := m.byTitle[titleKey(artist, title[0])]; !ok {
+				m.byTitle[titleKey(artist, title[0])] = song.ID
+			}
+		}
+	}
+}
+
+func (m *libraryMatcher) matchPath(path string) (uint, bool) {
+	if path == "" {
+		return 0, false
+	}
+	if location, err := url.Parse(path); err == nil && location.Scheme == "file" {
+		path = location.Path
+	}
+	path = strings.ReplaceAll(path, "\\", "/")
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(m.userPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	if id, ok := m.byPath[path]; ok {
+		return id, true
+	}
+	// playlists written on another machine share the end of the path with the library
+	for songPath, id := range m.byPath {
+		if strings.HasSuffix(path, "/"+songPath) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (m *libraryMatcher) match(entry playlist.Entry) (uint, bool) {
+	if id, ok := m.matchPath(entry.Path); ok {
+		return id, true
+	}
+	if entry.Isrc != "" {
+		if id, ok := m.byIsrc[strings.ToUpper(entry.Isrc)]; ok {
+			return id, true
+		}
+	}
+	if entry.Title == "" || entry.Artist == "" {
+		return 0, false
+	}
+	if m.byTitle == nil {
+		m.loadTitles()
+	}
+	for _, artist := range strings.Split(entry.Artist, ",") {
+		if id, ok := m.byTitle[titleKey(artist, entry.Title)]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// ImportPlaylist creates a playlist from a playlist file or a csv/json export, the entries missing from the library are reported
+func ImportPlaylist(userId uint, filename string, reader io.Reader, name string) (models.ResponsePlaylistImport, error) {
+	format, ok := playlist.FormatOf(filename)
+	if !ok {
+		return models.ResponsePlaylistImport{}, fmt.Errorf("services.ImportPlaylist: %w", fmt.Errorf("unsupported playlist file %q", filename))
+	}
+	title, entries, err := playlist.Parse(format, reader)
+	if err != nil {
+		return models.ResponsePlaylistImport{}, fmt.Errorf("services.ImportPlaylist: %w", err)
+	}
+	if name == "" {
+		name = title
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+
+	matcher, err := newLibraryMatcher(userId)
+	if err != nil {
+		return models.ResponsePlaylistImport{}, fmt.Errorf("services.ImportPlaylist: %w", err)
+	}
+
+	result := models.ResponsePlaylistImport{Unmatched: make([]string, 0)}
+	songIds := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		if id, ok := matcher.match(entry); ok {
+			songIds = append(songIds, id)
+			continue
+		}
+		label := entry.Path
+		if entry.Title != "" {
+			label = entry.Title
+			if entry.Artist != "" {
+				label = entry.Artist + " - " + label
+			}
+		}
+		result.Unmatched = append(result.Unmatched, label)
+	}
+	result.Matched = len(songIds)
+
+	created, err := CreatePlaylist(userId, name)
+	if err != nil {
+		return models.ResponsePlaylistImport{}, fmt.Errorf("services.ImportPlaylist: %w", err)
+	}
+	if err := AddPlaylistSongs(userId, created.ID, songIds); err != nil {
+		return models.ResponsePlaylistImport{}, fmt.Errorf("services.ImportPlaylist: %w", err)
+	}
+	created.Count = int64(len(songIds))
+	result.Playlist = created
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+)
+
+var playlistExtensions = []string{".m3u", ".m3u8", ".xspf"}
+
+func isPlaylistFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, playlistExt := range playlistExtensions {
+		if ext == playlistExt {
+			return true
+		}
+	}
+	return false
+}
+
+// newPlaylistPath picks a playlist file name at the root of the library of the user that no file uses yet
+func newPlaylistPath(userId uint, title string, extension string) (string, error) {
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return "", fmt.Errorf("newPlaylistPath: %w", err)
+	}
+
+	name := strings.TrimSpace(strings.ReplaceAll(title, "/", "_"))
+	if name == "" || strings.HasPrefix(name, ".") {
+		name = "Playlist" + name
+	}
+
+	path := name + extension
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(userPath, path)); os.IsNotExist(err) {
+			return path, nil
+		}
+		path = fmt.Sprintf("%s (%d)%s", name, i, extension)
+	}
+}
+
+// writeM3U8 replaces the playlist file with the given library songs, the paths are relative
+// to the root of the library where the playlist file lives
+func writeM3U8(userId uint, path string, title string, entries []playlist.Entry) error {
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("writeM3U8: %w", err)
+	}
+
+	var content bytes.Buffer
+	if err := playlist.WriteM3U(&content, title, entries); err != nil {
+		return fmt.Errorf("writeM3U8: %w", err)
+	}
+	return writePlaylistFile(filepath.Join(userPath, path), content.Bytes())
+}
+
+func writePlaylistFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("writePlaylistFile: os.MkdirAll: %w", err)
+	}
+
+	file, err := utils.CreateTemp(filepath.Dir(path), "playlist-*")
+	if err != nil {
+		return fmt.Errorf("writePlaylistFile: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writePlaylistFile: file.Write: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writePlaylistFile: file.Close: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("writePlaylistFile: os.Chmod: %w", err)
+	}
+	if err := utils.RenameForce(tmpPath, path); err != nil {
+		return fmt.Errorf("writePlaylistFile: %w", err)
+	}
+	return nil
+}
+
+func removePlaylistFile(userId uint, path string) error {
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("removePlaylistFile: %w", err)
+	}
+	if err := os.Remove(filepath.Join(userPath, path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removePlaylistFile: os.Remove: %w", err)
+	}
+	return nil
+}
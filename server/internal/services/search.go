@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+const trackListWorkers = 4
+
+// getTrackList returns the isrcs of an album or a playlist, the provider responses are cached by the plugins
+// so the completeness of search results does not cost a provider call on every keystroke
+func getTrackList(ctx context.Context, userId uint, provider string, kind models.Type, id string) ([]string, bool) {
+	isrcs := make([]string, 0)
+	switch kind {
+	case models.TypeAlbum:
+		album, err := plugins.GetAlbum(ctx, userId, provider, id)
+		if err != nil {
+			return nil, false
+		}
+		for _, song := range album.Songs {
+			isrcs = append(isrcs, song.Isrc)
+		}
+	case models.TypePlaylist:
+		playlist, err := plugins.GetPlaylist(ctx, userId, provider, id)
+		if err != nil {
+			return nil, false
+		}
+		for _, song := range playlist.Songs {
+			isrcs = append(isrcs, song.Isrc)
+		}
+	default:
+		return nil, false
+	}
+	return isrcs, true
+}
+
+// NewSearchQuery turns the search bar input into the kinds to search, an empty kind searches everything
+func NewSearchQuery(q string, kind string, limit uint, offset uint) (models.SearchQuery, error) {
+	query := models.SearchQuery{Limit: limit, Offset: offset}
+	switch models.Type(kind) {
+	case "", "all":
+		query.Song, query.Album, query.Artist, query.Playlist = q, q, q, q
+	case models.TypeSong:
+		query.Song = q
+	case models.TypeAlbum:
+		query.Album = q
+	case models.TypeArtist:
+		query.Artist = q
+	case models.TypePlaylist:
+		query.Playlist = q
+	default:
+		return models.SearchQuery{}, fmt.Errorf("services.NewSearchQuery: invalid type %q", kind)
+	}
+	return query, nil
+}
+
+// searchProviders runs the search on every provider, the first working plugin of a provider answers for it
+func searchProviders(ctx context.Context, userId uint, query models.SearchQuery) map[string]models.SearchData {
+	results := make(map[string]models.SearchData)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for provider, pluginsList := range plugins.GetAllPluginsByProvider() {
+		wg.Go(func() {
+			for _, plugin := range pluginsList {
+				data, err := plugin.Search(ctx, userId, query)
+				if err == nil {
+					mu.Lock()
+					results[provider] = data
+					mu.Unlock()
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// Search queries every provider and flags what the user already owns or follows,
+// the ownership of every song, album and playlist is resolved with a single isrc query
+func Search(ctx context.Context, userId uint, query models.SearchQuery) (map[string]models.SearchData, error) {
+	results := searchProviders(ctx, userId, query)
+	var wg sync.WaitGroup
+
+	type listRef struct {
+		provider string
+		kind     models.Type
+		index    int
+		id       string
+		isrcs    []string
+	}
+	refs := make([]*listRef, 0)
+	for provider, data := range results {
+		for i, album := range data.Albums {
+			refs = append(refs, &listRef{provider: provider, kind: models.TypeAlbum, index: i, id: album.Id})
+		}
+		for i, playlist := range data.Playlists {
+			refs = append(refs, &listRef{provider: provider, kind: models.TypePlaylist, index: i, id: playlist.ID})
+		}
+	}
+
+	jobs := make(chan *listRef)
+	for range trackListWorkers {
+		wg.Go(func() {
+			for ref := range jobs {
+				ref.isrcs, _ = getTrackList(ctx, userId, ref.provider, ref.kind, ref.id)
+			}
+		})
+	}
+	for _, ref := range refs {
+		jobs <- ref
+	}
+	close(jobs)
+	wg.Wait()
+
+	isrcs := make([]string, 0)
+	for _, data := range results {
+		for _, song := range data.Songs {
+			if song.Isrc != "" {
+				isrcs = append(isrcs, song.Isrc)
+			}
+		}
+	}
+	for _, ref := range refs {
+		isrcs = append(isrcs, ref.isrcs...)
+	}
+	ownedList, err := repository.ListOwnedISRCsByUserID(userId, isrcs)
+	if err != nil {
+		return nil, fmt.Errorf("services.Search: %w", err)
+	}
+	owned := make(map[string]bool, len(ownedList))
+	for _, isrc := range ownedList {
+		owned[isrc] = true
+	}
+
+	follows, err := repository.ListFollowsByUserID(userId)
+	if err != nil {
+		return nil, fmt.Errorf("services.Search: %w", err)
+	}
+	followed := make(map[string]uint, len(follows))
+	for _, follow := range follows {
+		followed[follow.Provider+"/"+follow.ArtistId] = follow.ID
+	}
+
+	for provider, data := range results {
+		for i, song := range data.Songs {
+			data.Songs[i].Downloaded = song.Isrc != "" && owned[song.Isrc]
+		}
+		for i, artist := range data.Artists {
+			data.Artists[i].Followed = followed[provider+"/"+artist.Id]
+		}
+	}
+	for _, ref := range refs {
+		complete := len(ref.isrcs) > 0
+		for _, isrc := range ref.isrcs {
+			if isrc == "" || !owned[isrc] {
+				complete = false
+				break
+			}
+		}
+		switch ref.kind {
+		case models.TypeAlbum:
+			results[ref.provider].Albums[ref.index].Downloaded = complete
+		case models.TypePlaylist:
+			results[ref.provider].Playlists[ref.index].Downloaded = complete
+		}
+	}
+	return results, nil
+}
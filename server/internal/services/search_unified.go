@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+)
+
+const (
+	unifiedLocalLimit   = 200
+	unifiedProviderPage = 100
+	unifiedLocalBoost   = 0.05
+	unifiedPopularity   = 0.1
+)
+
+// relevance scores a hit against the search, the best of the title alone and of the title with its artists
+func relevance(q string, title string, artists []string) float64 {
+	score := similarity(q, title)
+	if len(artists) > 0 {
+		score = math.Max(score, similarity(q, artists[0]+" "+title))
+		score = math.Max(score, similarity(q, title+" "+artists[0]))
+	}
+	return score
+}
+
+func popularityBoost(popularity uint) float64 {
+	return unifiedPopularity * float64(min(popularity, 100)) / 100
+}
+
+func albumKey(title string, artists []string) string {
+	key := normalizeText(title)
+	if len(artists) > 0 {
+		key += "|" + normalizeText(artists[0])
+	}
+	return key
+}
+
+// songFromPath reads the album artist, album and title out of the library layout of GetSongPathByTags,
+// a search ranks the local songs without opening their files
+func songFromPath(info models.Song) models.ResponseSong {
+	parts := strings.Split(filepath.ToSlash(info.Path), "/")
+	title := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(info.Path))
+	if number, rest, ok := strings.Cut(title, " - "); ok && strings.Trim(number, "0123456789") == "" {
+		title = rest
+	}
+	song := models.ResponseSong{ID: info.ID, Isrc: info.Isrc, AudioQuality: info.AudioQuality, Title: title, Artists: []string{}, AlbumArtists: []string{}}
+	if len(parts) >= 3 {
+		song.Album = parts[len(parts)-2]
+		song.AlbumArtists = []string{parts[len(parts)-3]}
+		song.Artists = song.AlbumArtists
+	}
+	return song
+}
+
+// sortedHits orders the hits by score, the key of the hit breaks the ties so the pages stay stable
+func sortedHits[T any](hits map[string]*T, score func(*T) float64) []T {
+	keys := slices.Sorted(maps.Keys(hits))
+	sort.SliceStable(keys, func(i, j int) bool { return score(hits[keys[i]]) > score(hits[keys[j]]) })
+	sorted := make([]T, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, *hits[key])
+	}
+	return sorted
+}
+
+func paginate[T any](items []T, limit uint, offset uint) []T {
+	if offset >= uint(len(items)) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit > 0 && uint(len(items)) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+type unifiedBuilder struct {
+	q       string
+	songs   map[string]*models.UnifiedSong
+	albums  map[string]*models.UnifiedAlbum
+	artists map[string]*models.UnifiedArtist
+}
+
+func (b *unifiedBuilder) song(key string) *models.UnifiedSong {
+	hit, ok := b.songs[key]
+	if !ok {
+		hit = &models.UnifiedSong{Sources: make([]models.UnifiedSource, 0), Local: make([]models.UnifiedLocal, 0)}
+		b.songs[key] = hit
+	}
+	return hit
+}
+
+func (b *unifiedBuilder) album(key string) *models.UnifiedAlbum {
+	hit, ok := b.albums[key]
+	if !ok {
+		hit = &models.UnifiedAlbum{Sources: make([]models.UnifiedSource, 0), Local: make([]models.UnifiedLocal, 0)}
+		b.albums[key] = hit
+	}
+	return hit
+}
+
+func (b *unifiedBuilder) addProvider(provider string, data models.SearchData) {
+	for _, song := range data.Songs {
+		key := strings.ToUpper(song.Isrc)
+		if key == "" {
+			key = provider + "/" + song.Id
+		}
+		artists := make([]string, 0, len(song.Artists))
+		for _, artist := range song.Artists {
+			artists = append(artists, artist.Name)
+		}
+
+		hit := b.song(key)
+		if hit.Title == "" {
+			hit.Isrc, hit.Title, hit.Artists = song.Isrc, song.Title, artists
+			hit.Album, hit.Duration, hit.CoverUrl, hit.Explicit = song.Album.Title, song.Duration, song.Album.CoverUrl, song.Explicit
+		}
+		hit.Score = math.Max(hit.Score, relevance(b.q, song.Title, artists)+popularityBoost(song.Popularity))
+		hit.Sources = append(hit.Sources, models.UnifiedSource{Provider: provider, Id: song.Id, AudioQuality: song.AudioQuality.Name})
+	}
+
+	for _, album := range data.Albums {
+		artists := make([]string, 0, len(album.Artists))
+		for _, artist := range album.Artists {
+			artists = append(artists, artist.Name)
+		}
+
+		hit := b.album(albumKey(album.Title, artists))
+		if hit.Title == "" {
+			hit.Title, hit.Artists, hit.CoverUrl, hit.Explicit = album.Title, artists, album.CoverUrl, album.Explicit
+		}
+		hit.Score = math.Max(hit.Score, relevance(b.q, album.Title, artists)+popularityBoost(album.Popularity))
+		hit.Sources = append(hit.Sources, models.UnifiedSource{Provider: provider, Id: album.Id, AudioQuality: album.AudioQuality.Name})
+	}
+
+	for _, artist := range data.Artists {
+		key := normalizeText(artist.Name)
+		hit, ok := b.artists[key]
+		if !ok {
+			hit = &models.UnifiedArtist{Name: artist.Name, PictureUrl: artist.PictureUrl, Sources: make([]models.UnifiedSource, 0)}
+			b.artists[key] = hit
+		}
+		hit.Score = math.Max(hit.Score, similarity(b.q, artist.Name)+popularityBoost(artist.Popularity))
+		hit.Sources = append(hit.Sources, models.UnifiedSource{Provider: provider, Id: artist.Id})
+	}
+}
+
+// addLocal merges a library song into the hit of its isrc and the album of its path
+func (b *unifiedBuilder) addLocal(info models.Song, song models.ResponseSong, matched bool) {
+	local := models.UnifiedLocal{SongId: info.ID, Path: info.Path, AudioQuality: info.AudioQuality}
+
+	key := strings.ToUpper(info.Isrc)
+	if key == "" {
+		key = fmt.Sprintf("local/%d", info.ID)
+	}
+	if _, ok := b.songs[key]; ok || matched {
+		hit := b.song(key)
+		if hit.Title == "" {
+			hit.Isrc, hit.Title, hit.Artists, hit.Album, hit.Duration, hit.Explicit = song.Isrc, song.Title, song.Artists, song.Album, song.Duration, song.Explicit
+		}
+		if matched {
+			hit.Score = math.Max(hit.Score, relevance(b.q, song.Title, song.Artists)+unifiedLocalBoost)
+		}
+		hit.Local = append(hit.Local, local)
+	}
+
+	if song.Album == "" {
+		return
+	}
+	artists := song.AlbumArtists
+	if len(artists) == 0 {
+		artists = song.Artists
+	}
+	key = albumKey(song.Album, artists)
+	if _, ok := b.albums[key]; ok || matched {
+		hit := b.album(key)
+		if hit.Title == "" {
+			hit.Title, hit.Artists, hit.Explicit = song.Album, artists, song.Explicit
+		}
+		if matched {
+			hit.Score = math.Max(hit.Score, relevance(b.q, song.Album, artists)+unifiedLocalBoost)
+		}
+		hit.Local = append(hit.Local, local)
+	}
+}
+
+// UnifiedSearch merges the results of every provider with the library of the user, a recording found on
+// several providers is collapsed by isrc and every hit is ranked by the same relevance score
+func UnifiedSearch(ctx context.Context, userId uint, q string, limit uint, offset uint) (models.ResponseUnifiedSearch, error) {
+	b := &unifiedBuilder{
+		q:       q,
+		songs:   make(map[string]*models.UnifiedSong),
+		albums:  make(map[string]*models.UnifiedAlbum),
+		artists: make(map[string]*models.UnifiedArtist),
+	}
+
+	query, err := NewSearchQuery(q, "", min(offset+limit, unifiedProviderPage), 0)
+	if err != nil {
+		return models.ResponseUnifiedSearch{}, fmt.Errorf("services.UnifiedSearch: %w", err)
+	}
+	query.Playlist = ""
+	// the unified result has no downloaded flags, the plugins are asked directly
+	results := searchProviders(ctx, userId, query)
+	isrcs := make([]string, 0)
+	for provider, data := range results {
+		b.addProvider(provider, data)
+		for _, song := range data.Songs {
+			if song.Isrc != "" {
+				isrcs = append(isrcs, song.Isrc)
+			}
+		}
+	}
+
+	matched, err := repository.SearchSongByUserID(userId, strings.Fields(q), unifiedLocalLimit)
+	if err != nil {
+		return models.ResponseUnifiedSearch{}, fmt.Errorf("services.UnifiedSearch: %w", err)
+	}
+	owned, err := repository.ListSongsByUserIDByISRCs(userId, isrcs)
+	if err != nil {
+		return models.ResponseUnifiedSearch{}, fmt.Errorf("services.UnifiedSearch: %w", err)
+	}
+
+	locals := make(map[uint]models.Song)
+	for _, list := range []struct {
+		songs   []models.Song
+		matched bool
+	}{{matched, true}, {owned, false}} {
+		for _, info := range list.songs {
+			if _, ok := locals[info.ID]; ok {
+				continue
+			}
+			locals[info.ID] = info
+			b.addLocal(info, songFromPath(info), list.matched)
+		}
+	}
+
+	result := models.ResponseUnifiedSearch{Query: q, Limit: limit, Offset: offset}
+
+	songs := sortedHits(b.songs, func(hit *models.UnifiedSong) float64 { return hit.Score })
+	albums := sortedHits(b.albums, func(hit *models.UnifiedAlbum) float64 { return hit.Score })
+	artists := sortedHits(b.artists, func(hit *models.UnifiedArtist) float64 { return hit.Score })
+
+	result.Total = models.UnifiedTotal{Songs: len(songs), Albums: len(albums), Artists: len(artists)}
+	result.Songs = paginate(songs, limit, offset)
+	result.Albums = paginate(albums, limit, offset)
+	result.Artists = paginate(artists, limit, offset)
+	if err := readLocalHits(userId, locals, result.Songs, result.Albums); err != nil {
+		log.Println("services.UnifiedSearch:", err)
+	}
+	for i := range result.Songs {
+		result.Songs[i].Score = math.Round(result.Songs[i].Score*100) / 100
+	}
+	for i := range result.Albums {
+		result.Albums[i].Score = math.Round(result.Albums[i].Score*100) / 100
+	}
+	for i := range result.Artists {
+		result.Artists[i].Score = math.Round(result.Artists[i].Score*100) / 100
+	}
+	return result, nil
+}
+
+// readLocalHits fills the hits of the page found only in the library with the tags of their first song,
+// the other local songs keep what their path tells
+func readLocalHits(userId uint, locals map[uint]models.Song, songs []models.UnifiedSong, albums []models.UnifiedAlbum) error {
+	pending := slices.ContainsFunc(songs, func(hit models.UnifiedSong) bool { return len(hit.Sources) == 0 }) ||
+		slices.ContainsFunc(albums, func(hit models.UnifiedAlbum) bool { return len(hit.Sources) == 0 })
+	if !pending {
+		return nil
+	}
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("readLocalHits: %w", err)
+	}
+
+	read := func(local []models.UnifiedLocal) (models.ResponseSong, bool) {
+		if len(local) == 0 {
+			return models.ResponseSong{}, false
+		}
+		song, err := readLibrarySong(userPath, locals[local[0].SongId])
+		if err != nil {
+			log.Println("readLocalHits:", err)
+			return models.ResponseSong{}, false
+		}
+		return song, true
+	}
+	for i := range songs {
+		if len(songs[i].Sources) > 0 {
+			continue
+		}
+		if song, ok := read(songs[i].Local); ok {
+			songs[i].Title, songs[i].Artists, songs[i].Album = song.Title, song.Artists, song.Album
+			songs[i].Duration, songs[i].Explicit = song.Duration, song.Explicit
+		}
+	}
+	for i := range albums {
+		if len(albums[i].Sources) > 0 {
+			continue
+		}
+		if song, ok := read(albums[i].Local); ok {
+			albums[i].Title, albums[i].Explicit = song.Album, song.Explicit
+			if len(song.AlbumArtists) > 0 {
+				albums[i].Artists = song.AlbumArtists
+			}
+		}
+	}
+	return nil
+}
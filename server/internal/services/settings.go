@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/robfig/cron/v3"
+)
+
+func ApplyRequestSettings(settings *models.Settings, req models.RequestSettings) error {
+	if req.DownloadGlobalLimit != nil {
+		if *req.DownloadGlobalLimit < 1 {
+			return fmt.Errorf("ApplyRequestSettings: %w", errors.New("downloadGlobalLimit must be at least 1"))
+		}
+		settings.DownloadGlobalLimit = *req.DownloadGlobalLimit
+	}
+
+	if req.DownloadUserLimit != nil {
+		if *req.DownloadUserLimit < 1 {
+			return fmt.Errorf("ApplyRequestSettings: %w", errors.New("downloadUserLimit must be at least 1"))
+		}
+		settings.DownloadUserLimit = *req.DownloadUserLimit
+	}
+
+	if req.DownloadRetryLimit != nil {
+		if *req.DownloadRetryLimit > 10 {
+			return fmt.Errorf("ApplyRequestSettings: %w", errors.New("downloadRetryLimit must be at most 10"))
+		}
+		settings.DownloadRetryLimit = *req.DownloadRetryLimit
+	}
+
+	if req.DownloadRetryDelay != nil {
+		if *req.DownloadRetryDelay < 1 {
+			return fmt.Errorf("ApplyRequestSettings: %w", errors.New("downloadRetryDelay must be at least 1 second"))
+		}
+		settings.DownloadRetryDelay = *req.DownloadRetryDelay
+	}
+
+	if req.AutoFetchSchedule != nil {
+		if _, err := cron.ParseStandard(*req.AutoFetchSchedule); err != nil {
+			return fmt.Errorf("ApplyRequestSettings: autoFetchSchedule: %w", err)
+		}
+		settings.AutoFetchSchedule = *req.AutoFetchSchedule
+	}
+
+	// a ttl of 0 disables the cache of that kind
+	if req.CacheSongTTL != nil {
+		settings.CacheSongTTL = *req.CacheSongTTL
+	}
+	if req.CacheAlbumTTL != nil {
+		settings.CacheAlbumTTL = *req.CacheAlbumTTL
+	}
+	if req.CacheArtistTTL != nil {
+		settings.CacheArtistTTL = *req.CacheArtistTTL
+	}
+	if req.CachePlaylistTTL != nil {
+		settings.CachePlaylistTTL = *req.CachePlaylistTTL
+	}
+
+	// a max size of 0 embeds the covers as the provider serves them
+	if req.CoverMaxSize != nil {
+		if *req.CoverMaxSize != 0 && *req.CoverMaxSize < 64 {
+			return fmt.Errorf("ApplyRequestSettings: %w", errors.New("coverMaxSize must be 0 or at least 64"))
+		}
+		settings.CoverMaxSize = *req.CoverMaxSize
+	}
+	if req.CoverFolderArt != nil {
+		settings.CoverFolderArt = *req.CoverFolderArt
+	}
+	if req.CoverArtistArt != nil {
+		settings.CoverArtistArt = *req.CoverArtistArt
+	}
+
+	// a retention of 0 keeps the trash until it is emptied by hand
+	if req.TrashRetentionDays != nil {
+		settings.TrashRetentionDays = *req.TrashRetentionDays
+	}
+
+	return nil
+}
+
+// ValidateAutoFetchSchedule accepts an empty schedule, the user then follows the global one
+func ValidateAutoFetchSchedule(schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return fmt.Errorf("ValidateAutoFetchSchedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/playlist"
+)
+
+func Subscribe(ctx context.Context, userId uint, req models.RequestSubscription) (models.PlaylistSubscription, error) {
+	playlist, err := plugins.GetPlaylist(ctx, userId, req.Provider, req.Id)
+	if err != nil {
+		return models.PlaylistSubscription{}, fmt.Errorf("services.Subscribe: %w", err)
+	}
+
+	path, err := newPlaylistPath(userId, playlist.Title, ".m3u8")
+	if err != nil {
+		return models.PlaylistSubscription{}, fmt.Errorf("services.Subscribe: %w", err)
+	}
+
+	subscription := models.PlaylistSubscription{
+		UserId:      userId,
+		Provider:    req.Provider,
+		PlaylistId:  req.Id,
+		Title:       playlist.Title,
+		CoverUrl:    playlist.CoverURL,
+		Path:        path,
+		DropRemoved: req.DropRemoved,
+		Songs:       make([]models.SubscriptionSong, 0),
+	}
+	if err := repository.AddSubscription(&subscription); err != nil {
+		return models.PlaylistSubscription{}, fmt.Errorf("services.Subscribe: %w", err)
+	}
+
+	if _, err := syncSubscription(ctx, &subscription, playlist); err != nil {
+		return subscription, fmt.Errorf("services.Subscribe: %w", err)
+	}
+	return subscription, nil
+}
+
+func subscriptionEntry(song models.SubscriptionSong) playlist.Entry {
+	return playlist.Entry{Isrc: song.Isrc, Artist: song.Artist, Title: song.Title}
+}
+
+// syncSubscription links every song of the playlist to the library and queues the ones missing and not
+// already downloading, so a failed download is tried again on the next sync. The removed songs the
+// subscription downloaded are dropped from the library when asked, then the playlist file is rewritten
+func syncSubscription(ctx context.Context, subscription *models.PlaylistSubscription, data models.PlaylistData) (models.ResponseSubscriptionSync, error) {
+	var result models.ResponseSubscriptionSync
+
+	previous := make(map[string]models.SubscriptionSong, len(subscription.Songs))
+	for _, song := range subscription.Songs {
+		previous[song.Id] = song
+	}
+
+	matcher, err := newLibraryMatcher(subscription.UserId)
+	if err != nil {
+		return result, fmt.Errorf("syncSubscription: %w", err)
+	}
+	paths := make(map[uint]string, len(matcher.songs))
+	for _, song := range matcher.songs {
+		paths[song.ID] = song.Path
+	}
+
+	songs := make([]models.SubscriptionSong, 0, len(data.Songs))
+	current := make(map[string]bool, len(data.Songs))
+	for _, song := range data.Songs {
+		artists := make([]string, 0, len(song.Artists))
+		for _, artist := range song.Artists {
+			artists = append(artists, artist.Name)
+		}
+		item := models.SubscriptionSong{Id: song.Id, Isrc: song.Isrc, Artist: strings.Join(artists, ","), Title: song.Title}
+		current[song.Id] = true
+
+		if old, ok := previous[song.Id]; ok {
+			item.Queued = old.Queued
+			if _, ok := paths[old.SongId]; ok {
+				item.SongId = old.SongId
+			}
+		} else {
+			result.Added++
+		}
+		if item.SongId == 0 {
+			item.SongId, _ = matcher.match(subscriptionEntry(item))
+		}
+		if item.SongId == 0 && !DownloadManager.InFlight(subscription.UserId, subscription.Provider, song.Id) {
+			DownloadManager.AddSong(subscription.UserId, subscription.Provider, song.Id, models.PriorityAutoFetch)
+			item.Queued = true
+			result.Queued++
+		}
+		songs = append(songs, item)
+	}
+
+	removed := make([]models.SubscriptionSong, 0)
+	for _, song := range subscription.Songs {
+		if !current[song.Id] {
+			removed = append(removed, song)
+		}
+	}
+	result.Removed = len(removed)
+
+	if subscription.DropRemoved && len(removed) > 0 {
+		dropped, err := dropSubscriptionSongs(subscription, songs, removed)
+		if err != nil {
+			log.Println("syncSubscription:", err)
+		}
+		result.Dropped = dropped
+	}
+
+	now := time.Now()
+	if err := repository.UpdateSubscriptionSongs(subscription.ID, data.Title, data.CoverURL, songs, now); err != nil {
+		return result, fmt.Errorf("syncSubscription: %w", err)
+	}
+	subscription.Title = data.Title
+	subscription.CoverUrl = data.CoverURL
+	subscription.Songs = songs
+	subscription.LastSyncedAt = &now
+
+	if err := writeSubscriptionFile(*subscription, paths); err != nil {
+		return result, fmt.Errorf("syncSubscription: %w", err)
+	}
+	return result, nil
+}
+
+// dropSubscriptionSongs trashes the removed songs the subscription downloaded itself, unless the playlist
+// still holds them, another subscription links them or a local playlist uses them
+func dropSubscriptionSongs(subscription *models.PlaylistSubscription, songs []models.SubscriptionSong, removed []models.SubscriptionSong) (int, error) {
+	kept := make(map[uint]bool)
+	for _, song := range songs {
+		kept[song.SongId] = true
+	}
+	subscriptions, err := repository.ListSubscriptionsByUserID(subscription.UserId)
+	if err != nil {
+		return 0, fmt.Errorf("dropSubscriptionSongs: %w", err)
+	}
+	for _, other := range subscriptions {
+		if other.ID == subscription.ID {
+			continue
+		}
+		for _, song := range other.Songs {
+			kept[song.SongId] = true
+		}
+	}
+
+	dropped := 0
+	for _, song := range removed {
+		if !song.Queued || song.SongId == 0 || kept[song.SongId] {
+			continue
+		}
+		kept[song.SongId] = true
+		playlists, err := repository.ListPlaylistsBySongID(song.SongId)
+		if err != nil {
+			log.Println("dropSubscriptionSongs:", err)
+			continue
+		}
+		if len(playlists) > 0 {
+			continue
+		}
+		if err := DeleteLibrarySong(subscription.UserId, song.SongId); err != nil {
+			log.Println("dropSubscriptionSongs:", err)
+			continue
+		}
+		dropped++
+	}
+	return dropped, nil
+}
+
+// subscriptionPaths returns the library path of every song linked to the subscription
+func subscriptionPaths(subscription models.PlaylistSubscription) (map[uint]string, error) {
+	ids := make([]uint, 0, len(subscription.Songs))
+	for _, song := range subscription.Songs {
+		if song.SongId != 0 {
+			ids = append(ids, song.SongId)
+		}
+	}
+	songs, err := repository.ListSongsByUserIDByIDs(subscription.UserId, ids)
+	if err != nil {
+		return nil, fmt.Errorf("subscriptionPaths: %w", err)
+	}
+	paths := make(map[uint]string, len(songs))
+	for _, song := range songs {
+		paths[song.ID] = song.Path
+	}
+	return paths, nil
+}
+
+// writeSubscriptionFile lists the linked songs of the playlist in the playlist order, the songs still
+// downloading are added by linkSubscriptionSong once saved
+func writeSubscriptionFile(subscription models.PlaylistSubscription, paths map[uint]string) error {
+	entries := make([]playlist.Entry, 0, len(subscription.Songs))
+	for _, song := range subscription.Songs {
+		if path, ok := paths[song.SongId]; ok {
+			entries = append(entries, playlist.Entry{Path: path})
+		}
+	}
+
+	if err := writeM3U8(subscription.UserId, subscription.Path, subscription.Title, entries); err != nil {
+		return fmt.Errorf("writeSubscriptionFile: %w", err)
+	}
+	return nil
+}
+
+// rewriteSubscriptionFile reads the paths of the linked songs and rewrites the playlist file
+func rewriteSubscriptionFile(subscription models.PlaylistSubscription) error {
+	paths, err := subscriptionPaths(subscription)
+	if err != nil {
+		return fmt.Errorf("rewriteSubscriptionFile: %w", err)
+	}
+	if err := writeSubscriptionFile(subscription, paths); err != nil {
+		return fmt.Errorf("rewriteSubscriptionFile: %w", err)
+	}
+	return nil
+}
+
+// linkSubscriptionSong links a freshly saved song to the subscriptions holding it, by provider id or
+// isrc, and rewrites their playlist files
+func linkSubscriptionSong(userId uint, provider string, songId string, song models.Song) {
+	subscriptions, err := repository.ListSubscriptionsByUserID(userId)
+	if err != nil {
+		log.Println("linkSubscriptionSong:", err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		linked := false
+		for i, item := range subscription.Songs {
+			if item.SongId != 0 {
+				continue
+			}
+			if (subscription.Provider == provider && item.Id == songId) || (song.Isrc != "" && strings.EqualFold(item.Isrc, song.Isrc)) {
+				subscription.Songs[i].SongId = song.ID
+				linked = true
+			}
+		}
+		if !linked {
+			continue
+		}
+		if err := repository.UpdateSubscriptionLinks(subscription.ID, subscription.Songs); err != nil {
+			log.Println("linkSubscriptionSong:", err)
+			continue
+		}
+		if err := rewriteSubscriptionFile(subscription); err != nil {
+			log.Println("linkSubscriptionSong:", err)
+		}
+	}
+}
+
+func SyncSubscription(ctx context.Context, userId uint, id uint) (models.ResponseSubscriptionSync, error) {
+	subscription, err := repository.GetSubscriptionByUserID(userId, id)
+	if err != nil {
+		return models.ResponseSubscriptionSync{}, fmt.Errorf("services.SyncSubscription: %w", err)
+	}
+	playlist, err := plugins.GetPlaylist(plugins.WithoutCache(ctx), userId, subscription.Provider, subscription.PlaylistId)
+	if err != nil {
+		return models.ResponseSubscriptionSync{}, fmt.Errorf("services.SyncSubscription: %w", err)
+	}
+	result, err := syncSubscription(ctx, &subscription, playlist)
+	if err != nil {
+		return result, fmt.Errorf("services.SyncSubscription: %w", err)
+	}
+	return result, nil
+}
+
+// SyncUserSubscriptions syncs every subscription of the user and returns the errors per playlist
+func SyncUserSubscriptions(ctx context.Context, userId uint) (int, []string) {
+	subscriptions, err := repository.ListSubscriptionsByUserID(userId)
+	if err != nil {
+		return 0, []string{err.Error()}
+	}
+
+	queued := 0
+	errs := make([]string, 0)
+	for _, subscription := range subscriptions {
+		result, err := SyncSubscription(ctx, userId, subscription.ID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", subscription.Title, err))
+			continue
+		}
+		queued += result.Queued
+	}
+	return queued, errs
+}
+
+func DeleteSubscription(userId uint, id uint) error {
+	subscription, err := repository.GetSubscriptionByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.DeleteSubscription: %w", err)
+	}
+	if err := removePlaylistFile(userId, subscription.Path); err != nil {
+		return fmt.Errorf("services.DeleteSubscription: %w", err)
+	}
+	if err := repository.DeleteSubscriptionByUserID(userId, id); err != nil {
+		return fmt.Errorf("services.DeleteSubscription: %w", err)
+	}
+	return nil
+}
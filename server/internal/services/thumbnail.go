@@ -0,0 +1,152 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/config"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/metadata"
+)
+
+var ThumbnailSizes = []uint{64, 256, 512}
+
+var (
+	ErrNoCover     = errors.New("song has no cover")
+	ErrInvalidSize = errors.New("invalid cover size")
+)
+
+// originalCoverName holds the embedded cover as is, next to the thumbnails of the album
+const originalCoverName = "original"
+
+// thumbnailUserDir keeps the thumbnails of a user outside of the user folders
+func thumbnailUserDir(userId uint) string {
+	return filepath.Join(config.LIBRARY_PATH, ".thumbnails", strconv.FormatUint(uint64(userId), 10))
+}
+
+// thumbnailDir holds the variants of an album, the songs of the same album folder share their thumbnails
+func thumbnailDir(userId uint, songPath string) string {
+	sum := sha1.Sum([]byte(filepath.Dir(songPath)))
+	return filepath.Join(thumbnailUserDir(userId), hex.EncodeToString(sum[:]))
+}
+
+// RemoveThumbnails drops the covers cached for the folder of a song, the songs still in the folder
+// get them generated again on their next request
+func RemoveThumbnails(userId uint, songPath string) {
+	if err := os.RemoveAll(thumbnailDir(userId, songPath)); err != nil {
+		log.Println("services.RemoveThumbnails:", err)
+	}
+}
+
+// writeThumbnail stores img resized to size, or untouched for a size of 0
+func writeThumbnail(path string, img []byte, size uint) error {
+	thumbnail := img
+	if size != 0 {
+		var err error
+		if thumbnail, err = metadata.ResizeCover(img, size); err != nil {
+			return fmt.Errorf("writeThumbnail: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("writeThumbnail: os.MkdirAll: %w", err)
+	}
+	tmp, err := utils.CreateTemp(filepath.Dir(path), "thumbnail-*")
+	if err != nil {
+		return fmt.Errorf("writeThumbnail: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(thumbnail); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writeThumbnail: tmp.Write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writeThumbnail: tmp.Close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writeThumbnail: os.Rename: %w", err)
+	}
+	return nil
+}
+
+// GetLibraryCoverInfo returns the cover of a song at one of the thumbnail sizes, or the embedded
+// original for a size of 0. Both are cached and generated again once the song file is newer than them
+func GetLibraryCoverInfo(userId uint, id uint, size uint) (models.LibraryCover, error) {
+	if size != 0 && !slices.Contains(ThumbnailSizes, size) {
+		return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w: %d", ErrInvalidSize, size)
+	}
+
+	song, err := repository.GetSongByUserID(userId, id)
+	if err != nil {
+		return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+	}
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+	}
+	songPath := filepath.Join(userPath, song.Path)
+	songInfo, err := os.Stat(songPath)
+	if err != nil {
+		return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+	}
+
+	dir := thumbnailDir(userId, song.Path)
+	name := originalCoverName
+	if size != 0 {
+		name = strconv.FormatUint(uint64(size), 10) + ".jpg"
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().Before(songInfo.ModTime()) {
+		img, err := metadata.ReadCover(songPath)
+		if err != nil {
+			return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+		}
+		if len(img) == 0 {
+			return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", ErrNoCover)
+		}
+		if err := writeThumbnail(path, img, size); err != nil {
+			return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+		}
+		if info, err = os.Stat(path); err != nil {
+			return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+		}
+	}
+
+	mime := "image/jpeg"
+	if size == 0 {
+		if mime, err = coverFileMime(path); err != nil {
+			return models.LibraryCover{}, fmt.Errorf("services.GetLibraryCoverInfo: %w", err)
+		}
+	}
+	modTime := info.ModTime().UTC()
+	return models.LibraryCover{
+		Path:    path,
+		Mime:    mime,
+		ETag:    fmt.Sprintf(`"%s-%d-%x"`, filepath.Base(dir)[:16], size, modTime.UnixNano()),
+		ModTime: modTime,
+	}, nil
+}
+
+func coverFileMime(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("coverFileMime: %w", err)
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("coverFileMime: %w", err)
+	}
+	return metadata.CoverMime(head[:n]), nil
+}
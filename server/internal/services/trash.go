@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/config"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const trashDirName = ".trash"
+
+var ErrTrashConflict = errors.New("a song already exists at the path of the deleted one")
+
+// trashDir keeps the deleted songs of a user next to the user folders, out of the scanned library
+func trashDir(userId uint) string {
+	return filepath.Join(config.LIBRARY_PATH, trashDirName, strconv.FormatUint(uint64(userId), 10))
+}
+
+// trashFile names the file after the song id, which the song keeps while it waits in the trash
+func trashFile(userId uint, song models.Song) string {
+	return filepath.Join(trashDir(userId), strconv.FormatUint(uint64(song.ID), 10)+filepath.Ext(song.Path))
+}
+
+// DeleteLibrarySong moves the song to the trash of the user, it can be restored until the trash is purged
+func DeleteLibrarySong(userId uint, id uint) error {
+	song, err := repository.GetSongByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
+	}
+
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
+	}
+
+	path := filepath.Join(userPath, song.Path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
+	}
+	if err := os.MkdirAll(trashDir(userId), 0755); err != nil {
+		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
+	}
+
+	item := models.TrashItem{
+		UserId:    userId,
+		SongId:    song.ID,
+		Size:      info.Size(),
+		DeletedAt: time.Now(),
+	}
+	if err := repository.TrashSongByUserID(userId, &item, func() error {
+		return utils.RenameSoft(path, trashFile(userId, song))
+	}); err != nil {
+		return fmt.Errorf("services.DeleteLibrarySong: %w", err)
+	}
+
+	RemoveThumbnails(userId, song.Path)
+	pruneEmptyDirs(userPath, filepath.Dir(path))
+	RefreshSongPlaylists(userId, song.ID)
+	return nil
+}
+
+func ListTrash(userId uint) (models.ResponseTrash, error) {
+	settings, err := repository.GetSettings()
+	if err != nil {
+		return models.ResponseTrash{}, fmt.Errorf("services.ListTrash: %w", err)
+	}
+	items, err := repository.ListTrashItemsByUserID(userId)
+	if err != nil {
+		return models.ResponseTrash{}, fmt.Errorf("services.ListTrash: %w", err)
+	}
+
+	trash := models.ResponseTrash{
+		Items:         make([]models.ResponseTrashItem, 0, len(items)),
+		RetentionDays: settings.TrashRetentionDays,
+	}
+	for _, item := range items {
+		response := models.ResponseTrashItem{
+			TrashItem:    item,
+			Path:         item.Song.Path,
+			Isrc:         item.Song.Isrc,
+			AudioQuality: item.Song.AudioQuality,
+		}
+		if settings.TrashRetentionDays != 0 {
+			purgeAt := item.DeletedAt.AddDate(0, 0, int(settings.TrashRetentionDays))
+			response.PurgeAt = &purgeAt
+		}
+		trash.Items = append(trash.Items, response)
+	}
+	return trash, nil
+}
+
+// RestoreTrashItem puts a deleted song back at its path under the same id, so its history and playlist
+// entries come back with it
+func RestoreTrashItem(userId uint, id uint) (models.Song, error) {
+	item, err := repository.GetTrashItemByUserID(userId, id)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w", err)
+	}
+
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w", err)
+	}
+
+	song := item.Song
+	path := filepath.Join(userPath, song.Path)
+	if _, err := os.Stat(path); err == nil {
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w: %s", ErrTrashConflict, song.Path)
+	}
+	if _, err := repository.GetSongByUserIDByPath(userId, song.Path); err == nil {
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w: %s", ErrTrashConflict, song.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w", err)
+	}
+
+	if err := repository.RestoreTrashItemByUserID(userId, item, func() error {
+		return utils.RenameSoft(trashFile(userId, song), path)
+	}); err != nil {
+		pruneEmptyDirs(userPath, filepath.Dir(path))
+		return models.Song{}, fmt.Errorf("services.RestoreTrashItem: %w", err)
+	}
+	song.DeletedAt = gorm.DeletedAt{}
+	RefreshSongPlaylists(userId, song.ID)
+	return song, nil
+}
+
+func purgeTrashItem(item *models.TrashItem) error {
+	if err := os.Remove(trashFile(item.UserId, item.Song)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("purgeTrashItem: %w", err)
+	}
+	RemoveThumbnails(item.UserId, item.Song.Path)
+	if err := repository.PurgeSong(item.SongId); err != nil {
+		return fmt.Errorf("purgeTrashItem: %w", err)
+	}
+	return nil
+}
+
+func PurgeTrashItem(userId uint, id uint) error {
+	item, err := repository.GetTrashItemByUserID(userId, id)
+	if err != nil {
+		return fmt.Errorf("services.PurgeTrashItem: %w", err)
+	}
+	if err := purgeTrashItem(&item); err != nil {
+		return fmt.Errorf("services.PurgeTrashItem: %w", err)
+	}
+	return nil
+}
+
+func EmptyTrash(userId uint) error {
+	items, err := repository.ListTrashItemsByUserID(userId)
+	if err != nil {
+		return fmt.Errorf("services.EmptyTrash: %w", err)
+	}
+	for _, item := range items {
+		if err := purgeTrashItem(&item); err != nil {
+			return fmt.Errorf("services.EmptyTrash: %w", err)
+		}
+	}
+	return nil
+}
+
+// PurgeExpiredTrash deletes for good the songs kept in a trash longer than the retention of the settings
+func PurgeExpiredTrash() {
+	settings, err := repository.GetSettings()
+	if err != nil {
+		log.Println("services.PurgeExpiredTrash:", err)
+		return
+	}
+	if settings.TrashRetentionDays == 0 {
+		return
+	}
+
+	items, err := repository.ListTrashItemsDeletedBefore(time.Now().AddDate(0, 0, -int(settings.TrashRetentionDays)))
+	if err != nil {
+		log.Println("services.PurgeExpiredTrash:", err)
+		return
+	}
+	for _, item := range items {
+		if err := purgeTrashItem(&item); err != nil {
+			log.Println("services.PurgeExpiredTrash:", err)
+		}
+	}
+}
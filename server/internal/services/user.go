@@ -1,7 +1,11 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -28,3 +32,33 @@ func CreateUserSession(userId uint, expiresIn time.Duration) (*models.UserSessio
 
 	return &session, nil
 }
+
+func ValidateQualityPolicy(policy models.QualityPolicy) error {
+	if len(policy.Preferences) == 0 {
+		return fmt.Errorf("validateQualityPolicy: %w", errors.New("preferences must contain at least one quality"))
+	}
+	for i, quality := range policy.Preferences {
+		if !quality.Valid() {
+			return fmt.Errorf("validateQualityPolicy: %w", fmt.Errorf("invalid quality %q", quality))
+		}
+		if slices.Contains(policy.Preferences[:i], quality) {
+			return fmt.Errorf("validateQualityPolicy: %w", fmt.Errorf("quality %q listed twice", quality))
+		}
+	}
+	if !policy.Minimum.Valid() {
+		return fmt.Errorf("validateQualityPolicy: %w", fmt.Errorf("invalid minimum quality %q", policy.Minimum))
+	}
+	if policy.Preferences[0].Rank() < policy.Minimum.Rank() {
+		return fmt.Errorf("validateQualityPolicy: %w", errors.New("preferred quality is below the minimum"))
+	}
+	return nil
+}
+
+// RemoveUserData drops the trash and the thumbnails kept outside of the folder of a deleted user
+func RemoveUserData(userId uint) {
+	for _, dir := range []string{trashDir(userId), thumbnailUserDir(userId)} {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Println("services.RemoveUserData:", err)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package autofetch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
@@ -13,106 +15,281 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
+const runHistoryLimit = 100
+
 type release struct {
-	userId   uint
-	provider string
-	albumId  string
+	userId      uint
+	provider    string
+	albumId     string
+	kind        models.ReleaseType
+	title       string
+	releaseDate string
+	coverUrl    string
+	explicit    bool
+	quality     models.QualityLevel
+}
+
+type scheduler struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	cron    *cron.Cron
+	entries map[uint]cron.EntryID
+	running sync.Map
+}
+
+var autoFetch *scheduler
+
+func artistReleases(userId uint, artist models.ArtistData) []release {
+	releases := make([]release, 0, len(artist.Albums)+len(artist.Ep)+len(artist.Singles))
+	for kind, albums := range map[models.ReleaseType][]models.ArtistDataAlbum{
+		models.ReleaseAlbum:  artist.Albums,
+		models.ReleaseEp:     artist.Ep,
+		models.ReleaseSingle: artist.Singles,
+	} {
+		for _, album := range albums {
+			releases = append(releases, release{
+				userId:      userId,
+				provider:    artist.Provider,
+				albumId:     album.Id,
+				kind:        kind,
+				title:       album.Title,
+				releaseDate: album.ReleaseDate,
+				coverUrl:    album.CoverUrl,
+				explicit:    album.Explicit,
+				quality:     models.QualityLevel(album.AudioQuality.Name),
+			})
+		}
+	}
+	return releases
 }
 
-func getNewReleasesOfArtist(ctx context.Context, userId uint, provider string, id string, lastFetchDate string) ([]release, error) {
-	var newReleases []release
-	plugins, ok := plugins.GetPluginByProvider(provider)
+// getNewReleasesOfArtist returns every new release of the artist and the ones kept by the filters of the follow
+func getNewReleasesOfArtist(ctx context.Context, follow models.Follow, lastFetchDate string) ([]release, []release, error) {
+	plugins, ok := plugins.GetPluginByProvider(follow.Provider)
 	if !ok {
-		return newReleases, fmt.Errorf("invalid provider name")
+		return nil, nil, fmt.Errorf("invalid provider name")
 	}
 
 	var artist models.ArtistData
 	var err error
 	for _, plugin := range plugins {
-		artist, err = plugin.Artist(ctx, userId, id)
+		artist, err = plugin.Artist(ctx, follow.UserId, follow.ArtistId)
 		if err == nil {
 			break
 		}
 	}
 	if err != nil {
-		return newReleases, err
+		return nil, nil, err
 	}
 
-	for _, album := range artist.Albums {
-		if album.ReleaseDate > lastFetchDate {
-			newReleases = append(newReleases, release{
-				userId:   userId,
-				provider: artist.Provider,
-				albumId:  album.Id,
-			})
+	all := artistReleases(follow.UserId, artist)
+	newReleases := make([]release, 0)
+	for _, release := range all {
+		if release.releaseDate > lastFetchDate {
+			newReleases = append(newReleases, release)
 		}
 	}
 
-	for _, ep := range artist.Ep {
-		if ep.ReleaseDate > lastFetchDate {
-			newReleases = append(newReleases, release{
-				userId:   userId,
-				provider: artist.Provider,
-				albumId:  ep.Id,
-			})
-		}
+	kept, err := filterReleases(ctx, follow, all, newReleases)
+	if err != nil {
+		return nil, nil, err
 	}
+	return newReleases, kept, nil
+}
 
-	for _, single := range artist.Singles {
-		if single.ReleaseDate > lastFetchDate {
-			newReleases = append(newReleases, release{
-				userId:   userId,
-				provider: artist.Provider,
-				albumId:  single.Id,
-			})
-		}
+// fetchFollow looks for the releases of a follow since its last successful fetch,
+// the fetch date only moves forward once the releases are queued so a failed follow is caught up next run
+func fetchFollow(ctx context.Context, follow models.Follow, startedAt time.Time) ([]release, []release, error) {
+	since := startedAt.Add(-24 * time.Hour)
+	if follow.LastFetchedAt != nil {
+		since = *follow.LastFetchedAt
 	}
 
-	return newReleases, nil
+	var newReleases, kept []release
+	var err error
+	for try := range 3 {
+		newReleases, kept, err = getNewReleasesOfArtist(ctx, follow, since.Format("2006-01-02"))
+		if err == nil {
+			break
+		}
+		log.Println("autofetch.fetchFollow: try ", try, ": ", err)
+	}
+	return newReleases, kept, err
 }
 
-func getNewReleases(ctx context.Context, follows []models.Follow, lastFetchDate string) ([]release, error) {
-	var newReleases []release
+// fetchUser runs the autofetch of every follow and playlist subscription of the user and records the run
+func fetchUser(ctx context.Context, userId uint, trigger models.AutoFetchTrigger) (models.AutoFetchRun, error) {
+	run := models.AutoFetchRun{
+		UserId:    userId,
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+		Errors:    make([]string, 0),
+	}
+
+	follows, err := repository.ListFollowsByUserID(userId)
+	if err != nil {
+		return models.AutoFetchRun{}, fmt.Errorf("autofetch.fetchUser: %w", err)
+	}
+	run.Follows = uint(len(follows))
+
 	for _, follow := range follows {
-		tmpReleases, _ := getNewReleasesOfArtist(ctx, follow.UserId, follow.Provider, follow.ArtistId, lastFetchDate)
-		newReleases = append(newReleases, tmpReleases...)
+		newReleases, kept, err := fetchFollow(ctx, follow, run.StartedAt)
+		if err != nil {
+			run.Failed++
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %s", follow.ArtistName, err))
+			continue
+		}
+
+		run.Found += uint(len(newReleases))
+		for _, release := range kept {
+			services.DownloadManager.AddAutoFetchAlbum(release.userId, release.provider, release.albumId, follow.Filters.MinimumQuality)
+			run.Queued++
+		}
+		addFeedEntries(ctx, follow, newReleases, kept)
+
+		if err := repository.UpdateFollowLastFetchedAt(follow.ID, run.StartedAt); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %s", follow.ArtistName, err))
+		}
+	}
+
+	queued, errs := services.SyncUserSubscriptions(ctx, userId)
+	run.Queued += uint(queued)
+	run.Errors = append(run.Errors, errs...)
+
+	run.FinishedAt = time.Now()
+	if err := repository.AddAutoFetchRun(&run); err != nil {
+		return run, fmt.Errorf("autofetch.fetchUser: %w", err)
 	}
-	return newReleases, nil
+	if err := repository.PruneAutoFetchRunsByUserID(userId, runHistoryLimit); err != nil {
+		log.Println("autofetch.fetchUser: ", err)
+	}
+	return run, nil
 }
 
-func fetch(ctx context.Context, lastFetchDate string) error {
-	follows, err := repository.ListFollows()
+func (s *scheduler) run(userId uint, trigger models.AutoFetchTrigger) (models.AutoFetchRun, error) {
+	if _, running := s.running.LoadOrStore(userId, struct{}{}); running {
+		return models.AutoFetchRun{}, fmt.Errorf("autofetch.run: %w", errors.New("autofetch already running"))
+	}
+	defer s.running.Delete(userId)
+
+	run, err := fetchUser(s.ctx, userId, trigger)
 	if err != nil {
-		return fmt.Errorf("fetch: %w", err)
+		log.Println("AutoFetch: user ", userId, ": ", err)
+	} else {
+		log.Println("AutoFetch: user ", userId, ": found ", run.Found, ", queued ", run.Queued, ", failed ", run.Failed)
 	}
+	return run, err
+}
 
-	releases, err := getNewReleases(ctx, follows, lastFetchDate)
+func scheduleOf(user models.User, settings *models.Settings) string {
+	if user.AutoFetchSchedule != "" {
+		return user.AutoFetchSchedule
+	}
+	return settings.AutoFetchSchedule
+}
+
+// reload registers one cron entry per user, with the user schedule or the global one
+func (s *scheduler) reload() ([]models.User, map[uint]cron.Schedule, error) {
+	settings, err := repository.GetSettings()
+	if err != nil {
+		return nil, nil, fmt.Errorf("autofetch.reload: %w", err)
+	}
+	users, err := repository.ListUsers()
 	if err != nil {
-		return fmt.Errorf("fetch: %w", err)
+		return nil, nil, fmt.Errorf("autofetch.reload: %w", err)
 	}
 
-	for _, release := range releases {
-		services.DownloadManager.AddAlbum(release.userId, release.provider, release.albumId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userId, entry := range s.entries {
+		s.cron.Remove(entry)
+		delete(s.entries, userId)
+	}
+
+	schedules := make(map[uint]cron.Schedule)
+	for _, user := range users {
+		schedule, err := cron.ParseStandard(scheduleOf(user, settings))
+		if err != nil {
+			log.Println("autofetch.reload: user ", user.ID, ": ", err)
+			continue
+		}
+		userId := user.ID
+		s.entries[userId] = s.cron.Schedule(schedule, cron.FuncJob(func() {
+			_, _ = s.run(userId, models.AutoFetchTriggerSchedule)
+		}))
+		schedules[userId] = schedule
 	}
-	return nil
+	return users, schedules, nil
 }
 
-func AutoFetch(ctx context.Context) *cron.Cron {
-	c := cron.New()
-	if _, err := c.AddFunc("0 1 * * *", func() {
-		lastFetchDate := time.Now().Add(-24 * time.Hour).Format("2006-01-02")
-		for try := range 3 {
-			if err := fetch(ctx, lastFetchDate); err != nil {
-				log.Println("AutoFetch: try ", try, ": ", err)
-			} else {
-				log.Println("AutoFetch: success")
+// catchUp runs the users whose oldest follow missed a scheduled window while the server was down
+func (s *scheduler) catchUp(users []models.User, schedules map[uint]cron.Schedule) {
+	now := time.Now()
+	for _, user := range users {
+		schedule, ok := schedules[user.ID]
+		if !ok {
+			continue
+		}
+		follows, err := repository.ListFollowsByUserID(user.ID)
+		if err != nil {
+			log.Println("autofetch.catchUp: ", err)
+			continue
+		}
+
+		missed := false
+		for _, follow := range follows {
+			if follow.LastFetchedAt == nil || schedule.Next(*follow.LastFetchedAt).Before(now) {
+				missed = true
 				break
 			}
 		}
-	}); err != nil {
-		log.Fatalln("AutoFetch: AddFunc: ", err)
+		if missed {
+			_, _ = s.run(user.ID, models.AutoFetchTriggerCatchUp)
+		}
+	}
+}
+
+// Reload applies a schedule change of the admin or of a user
+func Reload() error {
+	if autoFetch == nil {
+		return nil
+	}
+	_, _, err := autoFetch.reload()
+	return err
+}
+
+// Next returns the next scheduled run of a user
+func Next(userId uint) *time.Time {
+	if autoFetch == nil {
+		return nil
+	}
+	autoFetch.mu.Lock()
+	entry, ok := autoFetch.entries[userId]
+	autoFetch.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	next := autoFetch.cron.Entry(entry).Next
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+func AutoFetch(ctx context.Context) *cron.Cron {
+	autoFetch = &scheduler{
+		ctx:     ctx,
+		cron:    cron.New(),
+		entries: make(map[uint]cron.EntryID),
+	}
+
+	users, schedules, err := autoFetch.reload()
+	if err != nil {
+		log.Fatalln("AutoFetch: ", err)
 	}
 
-	c.Start()
-	return c
+	autoFetch.cron.Start()
+	go autoFetch.catchUp(users, schedules)
+	return autoFetch.cron
 }
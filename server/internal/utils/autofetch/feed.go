@@ -0,0 +1,39 @@
+package autofetch
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+// addFeedEntries records every new release of a follow, the queued ones are marked downloaded once their job is done
+func addFeedEntries(ctx context.Context, follow models.Follow, newReleases []release, kept []release) {
+	for _, r := range newReleases {
+		queued := slices.ContainsFunc(kept, func(k release) bool {
+			return k.provider == r.provider && k.albumId == r.albumId
+		})
+
+		entry := models.FeedEntry{
+			UserId:      follow.UserId,
+			Provider:    r.provider,
+			AlbumId:     r.albumId,
+			FollowId:    follow.ID,
+			ArtistId:    follow.ArtistId,
+			ArtistName:  follow.ArtistName,
+			Type:        r.kind,
+			Title:       r.title,
+			ReleaseDate: r.releaseDate,
+			CoverUrl:    r.coverUrl,
+			Explicit:    r.explicit,
+			Quality:     r.quality,
+			Queued:      queued,
+			Downloaded:  !queued && ownedRelease(ctx, follow.UserId, r.provider, r.albumId),
+		}
+		if err := repository.AddFeedEntry(&entry); err != nil {
+			log.Println("autofetch.addFeedEntries: ", err)
+		}
+	}
+}
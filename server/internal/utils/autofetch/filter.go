@@ -0,0 +1,142 @@
+package autofetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compilePatterns: %w", err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, title string) bool {
+	return slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(title)
+	})
+}
+
+func releaseKey(r release) string {
+	return string(r.kind) + ":" + strings.ToLower(strings.TrimSpace(r.title))
+}
+
+// albumIsrcs collects the isrcs of the albums and eps of the artist, a single is known when all
+// its tracks appear there or in the library
+func albumIsrcs(ctx context.Context, follow models.Follow, all []release) map[string]bool {
+	isrcs := make(map[string]bool)
+	for _, r := range all {
+		if r.kind == models.ReleaseSingle {
+			continue
+		}
+		album, err := plugins.GetAlbum(ctx, follow.UserId, r.provider, r.albumId)
+		if err != nil {
+			log.Println("autofetch.albumIsrcs: ", err)
+			continue
+		}
+		for _, song := range album.Songs {
+			if song.Isrc != "" {
+				isrcs[song.Isrc] = true
+			}
+		}
+	}
+	return isrcs
+}
+
+func singleIsKnown(ctx context.Context, follow models.Follow, single release, isrcs map[string]bool) bool {
+	album, err := plugins.GetAlbum(ctx, follow.UserId, single.provider, single.albumId)
+	if err != nil || len(album.Songs) == 0 {
+		return false
+	}
+	for _, song := range album.Songs {
+		if song.Isrc == "" {
+			return false
+		}
+		if isrcs[song.Isrc] {
+			continue
+		}
+		if _, err := repository.GetSongByUserIDByISRC(follow.UserId, song.Isrc); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// filterReleases applies the filters of the follow to its new releases,
+// all the releases of the artist are needed to find explicit twins and the albums of a single
+func filterReleases(ctx context.Context, follow models.Follow, all []release, newReleases []release) ([]release, error) {
+	filters := follow.Filters
+
+	include, err := compilePatterns(filters.IncludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("filterReleases: %w", err)
+	}
+	exclude, err := compilePatterns(filters.ExcludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("filterReleases: %w", err)
+	}
+
+	versions := make(map[string]map[bool]bool)
+	for _, r := range all {
+		key := releaseKey(r)
+		if versions[key] == nil {
+			versions[key] = make(map[bool]bool)
+		}
+		versions[key][r.explicit] = true
+	}
+
+	var isrcs map[string]bool
+	kept := make([]release, 0, len(newReleases))
+	for _, r := range newReleases {
+		if len(filters.ReleaseTypes) > 0 && !slices.Contains(filters.ReleaseTypes, r.kind) {
+			continue
+		}
+		if len(include) > 0 && !matchAny(include, r.title) {
+			continue
+		}
+		if matchAny(exclude, r.title) {
+			continue
+		}
+		if filters.MinimumQuality != "" && r.quality != "" && r.quality.Rank() < filters.MinimumQuality.Rank() {
+			continue
+		}
+
+		twins := versions[releaseKey(r)]
+		switch filters.Explicit {
+		case models.ExplicitExplicit:
+			if !r.explicit && twins[true] {
+				continue
+			}
+		case models.ExplicitClean:
+			if r.explicit && twins[false] {
+				continue
+			}
+		}
+
+		if filters.SkipSinglesOnAlbum && r.kind == models.ReleaseSingle {
+			if isrcs == nil {
+				isrcs = albumIsrcs(ctx, follow, all)
+			}
+			if singleIsKnown(ctx, follow, r, isrcs) {
+				continue
+			}
+		}
+
+		kept = append(kept, r)
+	}
+	return kept, nil
+}
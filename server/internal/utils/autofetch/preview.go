@@ -0,0 +1,110 @@
+package autofetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+// ownedRelease reports whether every track of the release is already in the library, with a single isrc
+// query per release, a track without isrc is never taken as owned
+func ownedRelease(ctx context.Context, userId uint, provider string, id string) bool {
+	album, err := plugins.GetAlbum(ctx, userId, provider, id)
+	if err != nil || len(album.Songs) == 0 {
+		return false
+	}
+	isrcs := make([]string, 0, len(album.Songs))
+	for _, song := range album.Songs {
+		if song.Isrc == "" {
+			return false
+		}
+		isrcs = append(isrcs, song.Isrc)
+	}
+	owned, err := repository.ListOwnedISRCsByUserID(userId, isrcs)
+	if err != nil {
+		log.Println("autofetch.ownedRelease:", err)
+		return false
+	}
+	for _, isrc := range isrcs {
+		if !slices.Contains(owned, isrc) {
+			return false
+		}
+	}
+	return true
+}
+
+func releaseItem(ctx context.Context, follow models.Follow, r release) models.ReleaseItem {
+	return models.ReleaseItem{
+		FollowId:     follow.ID,
+		ArtistId:     follow.ArtistId,
+		ArtistName:   follow.ArtistName,
+		Provider:     r.provider,
+		Id:           r.albumId,
+		Type:         r.kind,
+		Title:        r.title,
+		ReleaseDate:  r.releaseDate,
+		CoverUrl:     r.coverUrl,
+		Explicit:     r.explicit,
+		AudioQuality: r.quality,
+		Owned:        ownedRelease(ctx, follow.UserId, r.provider, r.albumId),
+	}
+}
+
+// getNewReleases gathers the new releases of the follows the way a run would, since overrides the last fetch of every follow
+func getNewReleases(ctx context.Context, follows []models.Follow, since *time.Time) ([]models.ReleaseItem, []string) {
+	items := make([]models.ReleaseItem, 0)
+	errs := make([]string, 0)
+	now := time.Now()
+	for _, follow := range follows {
+		if since != nil {
+			follow.LastFetchedAt = since
+		}
+		_, releases, err := fetchFollow(ctx, follow, now)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", follow.ArtistName, err))
+			continue
+		}
+		for _, r := range releases {
+			items = append(items, releaseItem(ctx, follow, r))
+		}
+	}
+	return items, errs
+}
+
+// Preview lists what a run of the user would queue without queueing anything
+func Preview(ctx context.Context, userId uint, since *time.Time) (models.ResponseReleases, error) {
+	follows, err := repository.ListFollowsByUserID(userId)
+	if err != nil {
+		return models.ResponseReleases{}, fmt.Errorf("autofetch.Preview: %w", err)
+	}
+
+	items, errs := getNewReleases(ctx, follows, since)
+	return models.ResponseReleases{Since: since, Items: items, Errors: errs}, nil
+}
+
+// RunAll triggers the autofetch of every user in the background
+func RunAll() error {
+	if autoFetch == nil {
+		return fmt.Errorf("autofetch.RunAll: %w", errors.New("autofetch not started"))
+	}
+	users, err := repository.ListUsers()
+	if err != nil {
+		return fmt.Errorf("autofetch.RunAll: %w", err)
+	}
+
+	go func() {
+		for _, user := range users {
+			if _, err := autoFetch.run(user.ID, models.AutoFetchTriggerManual); err != nil {
+				log.Println("autofetch.RunAll: ", err)
+			}
+		}
+	}()
+	return nil
+}
@@ -29,3 +29,16 @@ func Fetch(ctx context.Context, url string) (*http.Response, error) {
 	}
 	return resp, nil
 }
+
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return e.Status
+}
+
+func NewStatusError(resp *http.Response) error {
+	return &StatusError{Code: resp.StatusCode, Status: resp.Status}
+}
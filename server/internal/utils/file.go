@@ -4,8 +4,27 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
+// tempPrefix marks the files written next to the songs of a library before they take their place
+const tempPrefix = ".musicshack-"
+
+// CreateTemp creates a temporary file in dir the library sync never takes for a song, pattern works
+// as with os.CreateTemp
+func CreateTemp(dir string, pattern string) (*os.File, error) {
+	file, err := os.CreateTemp(dir, tempPrefix+pattern)
+	if err != nil {
+		return nil, fmt.Errorf("utils.CreateTemp: %w", err)
+	}
+	return file, nil
+}
+
+// IsTemp reports whether the file name is one of CreateTemp
+func IsTemp(name string) bool {
+	return strings.HasPrefix(name, tempPrefix)
+}
+
 func RenameForce(src, dst string) error {
 	//linux
 	if os.Rename(src, dst) == nil {
@@ -0,0 +1,182 @@
+package metadata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
+)
+
+const coverQuality = 90
+
+var (
+	FolderArtNames = []string{"cover.jpg", "folder.jpg"}
+	ArtistArtName  = "artist.jpg"
+)
+
+// IsArtFile reports the images written next to the songs, so the library scan and cleanup skip them
+func IsArtFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".webp", ".gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// CoverMime detects the type of an image from its content, jpeg when it cannot be told
+func CoverMime(img []byte) string {
+	mime := http.DetectContentType(img)
+	if !strings.HasPrefix(mime, "image/") {
+		return "image/jpeg"
+	}
+	return mime
+}
+
+// scale resizes src to width x height by averaging the source pixels covered by each destination pixel
+func scale(src image.Image, width int, height int) *image.RGBA {
+	bounds := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	}
+	srcW, srcH := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+		for x := range width {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(rgba.Pix[offset])
+					g += uint32(rgba.Pix[offset+1])
+					b += uint32(rgba.Pix[offset+2])
+					a += uint32(rgba.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// ResizeCover fits an image in a size x size box and encodes it as jpeg, a smaller jpeg is returned untouched
+func ResizeCover(img []byte, size uint) ([]byte, error) {
+	decoded, format, err := image.Decode(bytes.NewReader(img))
+	if err != nil {
+		return nil, fmt.Errorf("metadata.ResizeCover: image.Decode: %w", err)
+	}
+
+	width, height := decoded.Bounds().Dx(), decoded.Bounds().Dy()
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("metadata.ResizeCover: %w", errors.New("empty image"))
+	}
+	if size == 0 || (uint(max(width, height)) <= size) {
+		if format == "jpeg" {
+			return img, nil
+		}
+	} else if width >= height {
+		height = max(1, height*int(size)/width)
+		width = int(size)
+	} else {
+		width = max(1, width*int(size)/height)
+		height = int(size)
+	}
+
+	var out image.Image = decoded
+	if width != decoded.Bounds().Dx() || height != decoded.Bounds().Dy() {
+		out = scale(decoded, width, height)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: coverQuality}); err != nil {
+		return nil, fmt.Errorf("metadata.ResizeCover: jpeg.Encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeArt writes an image as jpeg under dir unless the file already exists
+func writeArt(dir string, name string, img []byte) error {
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	data, err := ResizeCover(img, 0)
+	if err != nil {
+		return fmt.Errorf("metadata.writeArt: %w", err)
+	}
+	tmp, err := utils.CreateTemp(dir, "art-*")
+	if err != nil {
+		return fmt.Errorf("metadata.writeArt: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("metadata.writeArt: tmp.Write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("metadata.writeArt: tmp.Close: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("metadata.writeArt: os.Chmod: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("metadata.writeArt: os.Rename: %w", err)
+	}
+	return nil
+}
+
+// WriteFolderArt writes cover.jpg and folder.jpg in the album folder once
+func WriteFolderArt(dir string, img []byte) error {
+	for _, name := range FolderArtNames {
+		if err := writeArt(dir, name, img); err != nil {
+			return fmt.Errorf("metadata.WriteFolderArt: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteArtistArt writes artist.jpg in the artist folder once
+func WriteArtistArt(dir string, img []byte) error {
+	if err := writeArt(dir, ArtistArtName, img); err != nil {
+		return fmt.Errorf("metadata.WriteArtistArt: %w", err)
+	}
+	return nil
+}
+
+// RemoveArtOnlyDir drops the art written by the library from a folder holding nothing else,
+// so the folder can be removed with its last song
+func RemoveArtOnlyDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !(slices.Contains(FolderArtNames, entry.Name()) || entry.Name() == ArtistArtName) {
+			return
+		}
+	}
+	for _, entry := range entries {
+		_ = os.Remove(filepath.Join(dir, entry.Name()))
+	}
+}
@@ -5,16 +5,21 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils"
 	"go.senan.xyz/taglib"
 )
 
-func getCover(ctx context.Context, url string) (io.Reader, error) {
+func getCover(ctx context.Context, url string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -29,7 +34,7 @@ func getCover(ctx context.Context, url string) (io.Reader, error) {
 		return nil, fmt.Errorf("metadata.getCover: io.ReadAll: %w", err)
 	}
 
-	return bytes.NewReader(buf), nil
+	return buf, nil
 }
 
 func WriteTags(path string, tags map[string][]string, trunc bool) error {
@@ -46,11 +51,21 @@ func WriteTags(path string, tags map[string][]string, trunc bool) error {
 	}
 }
 
+// WriteCover embeds an image as the front cover, downscaled to the configured maximum size
 func WriteCover(path string, reader io.Reader) error {
 	img, err := io.ReadAll(reader)
 	if err != nil {
 		return fmt.Errorf("metadata.WriteCover: io.ReadAll: %w", err)
 	}
+	if settings, err := repository.GetSettings(); err != nil {
+		log.Println("metadata.WriteCover:", err)
+	} else if settings.CoverMaxSize > 0 {
+		if resized, err := ResizeCover(img, settings.CoverMaxSize); err != nil {
+			log.Println("metadata.WriteCover:", err)
+		} else {
+			img = resized
+		}
+	}
 	if err := taglib.WriteImage(path, img); err != nil {
 		return fmt.Errorf("metadata.WriteCover: taglib.WriteImage: %w", err)
 	} else {
@@ -58,6 +73,57 @@ func WriteCover(path string, reader io.Reader) error {
 	}
 }
 
+// writeLibraryArt saves the album cover in the album folder and the artist picture in the artist
+// folder, a failure only costs the art so it is logged
+func writeLibraryArt(ctx context.Context, userId uint, path string, data models.SongData, cover []byte) {
+	settings, err := repository.GetSettings()
+	if err != nil {
+		log.Println("metadata.writeLibraryArt:", err)
+		return
+	}
+
+	// the art only goes in the album and artist folders of the user, never above them
+	userPath, err := utils.GetUserPath(userId)
+	if err != nil {
+		log.Println("metadata.writeLibraryArt:", err)
+		return
+	}
+	userPath, _ = filepath.Abs(userPath)
+	path, _ = filepath.Abs(path)
+	rel, err := filepath.Rel(userPath, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	depth := strings.Count(rel, string(filepath.Separator))
+
+	albumDir := filepath.Dir(path)
+	if settings.CoverFolderArt && depth >= 1 {
+		if err := WriteFolderArt(albumDir, cover); err != nil {
+			log.Println("metadata.writeLibraryArt:", err)
+		}
+	}
+
+	artistDir := filepath.Dir(albumDir)
+	if !settings.CoverArtistArt || depth < 2 || len(data.Artists) == 0 {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(artistDir, ArtistArtName)); err == nil {
+		return
+	}
+	artist, err := plugins.GetArtist(ctx, userId, data.Provider, data.Artists[0].Id)
+	if err != nil || artist.PictureUrl == "" {
+		return
+	}
+	img, err := getCover(ctx, artist.PictureUrl)
+	if err != nil {
+		log.Println("metadata.writeLibraryArt:", err)
+		return
+	}
+	if err := WriteArtistArt(artistDir, img); err != nil {
+		log.Println("metadata.writeLibraryArt:", err)
+	}
+}
+
 func FormatMetadata(ctx context.Context, userId uint, path string, data models.SongData) error {
 	album, err := plugins.GetAlbum(ctx, userId, data.Provider, data.Album.Id)
 	if err != nil {
@@ -99,9 +165,39 @@ func FormatMetadata(ctx context.Context, userId uint, path string, data models.S
 		models.TagTrackGain:    {trackGain},
 		models.TagTrackPeak:    {trackPeak},
 		models.TagISRC:         {data.Isrc},
+		models.TagTrackTotal:   {strconv.FormatUint(uint64(album.NumberTracks), 10)},
+		models.TagDiscTotal:    {strconv.FormatUint(uint64(album.NumberVolumes), 10)},
+	}
+
+	// the optional credits are only written when the provider knows them, so a sparse
+	// provider never blanks what the file already holds
+	genres := data.Genres
+	if len(genres) == 0 {
+		genres = album.Genres
+	}
+	copyright := data.Copyright
+	if copyright == "" {
+		copyright = album.Copyright
+	}
+	optional := map[string][]string{
+		models.TagGenre:     genres,
+		models.TagLabel:     {album.Label},
+		models.TagCopyright: {copyright},
+		models.TagBarcode:   {album.Upc},
+		models.TagComposer:  data.Composers,
+		models.TagLyricist:  data.Lyricists,
+		models.TagKey:       {data.Key},
+	}
+	if data.Bpm > 0 {
+		optional[models.TagBPM] = []string{strconv.FormatUint(uint64(data.Bpm), 10)}
+	}
+	for tag, values := range optional {
+		if len(values) > 0 && values[0] != "" {
+			tags[tag] = values
+		}
 	}
 
-	if err := WriteTags(path, tags, false); err != nil {
+	if err := WriteTags(path, ApplyProfile(GetUserProfile(userId), tags), false); err != nil {
 		return fmt.Errorf("metadata.FormatMetadata: %w", err)
 	}
 
@@ -110,9 +206,10 @@ func FormatMetadata(ctx context.Context, userId uint, path string, data models.S
 		return fmt.Errorf("metadata.FormatMetadata: %w", err)
 	}
 
-	if err := WriteCover(path, img); err != nil {
+	if err := WriteCover(path, bytes.NewReader(img)); err != nil {
 		return fmt.Errorf("metadata.FormatMetadata: %w", err)
 	}
+	writeLibraryArt(ctx, userId, path, data, img)
 	return nil
 }
 
@@ -120,7 +217,7 @@ func ReadTags(path string) (map[string][]string, error) {
 	if tags, err := taglib.ReadTags(path); err != nil {
 		return tags, fmt.Errorf("metadata.ReadTags: %w", err)
 	} else {
-		return tags, nil
+		return normalizeTags(tags), nil
 	}
 }
 
@@ -132,3 +229,25 @@ func ReadCover(path string) ([]byte, error) {
 		return img, nil
 	}
 }
+
+// ReadQuality estimates the quality level of a file from its container and audio properties,
+// lossless files above 48kHz are considered hi-res as the bit depth is not exposed
+func ReadQuality(path string) (models.QualityLevel, error) {
+	properties, err := taglib.ReadProperties(path)
+	if err != nil {
+		return "", fmt.Errorf("metadata.ReadQuality: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac", ".wav", ".aiff", ".aif", ".alac":
+		if properties.SampleRate > 48000 {
+			return models.QualityHiRes, nil
+		}
+		return models.QualityLossless, nil
+	default:
+		if properties.Bitrate >= 192 {
+			return models.QualityHigh, nil
+		}
+		return models.QualityLow, nil
+	}
+}
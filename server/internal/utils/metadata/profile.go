@@ -0,0 +1,238 @@
+package metadata
+
+import (
+	"log"
+	"strings"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/repository"
+)
+
+const (
+	tagArtist       = "ARTIST"
+	tagAlbumArtist  = "ALBUMARTIST"
+	tagDate         = "DATE"
+	tagYear         = "YEAR"
+	tagOriginalDate = "ORIGINALDATE"
+)
+
+type dateMode int
+
+const (
+	dateNone dateMode = iota
+	dateFull
+	dateYear
+)
+
+type tagProfile struct {
+	artists      string // key of the artists list
+	albumArtists string
+	display      bool   // also writes the joined ARTIST and ALBUMARTIST display values
+	multiValue   bool   // lists are written as several values instead of a joined one
+	separator    string // joins the lists, for the display values or when multiValue is off
+	releaseDate  bool
+	date         dateMode
+	year         bool
+	originalDate bool
+	clean        string // ITUNESADVISORY values of a clean and an explicit song
+	explicit     string
+}
+
+var tagProfiles = map[models.TagProfile]tagProfile{
+	// the default profile keeps the true/false advisory the files of the library were always written with
+	models.TagProfileNavidrome: {
+		artists: models.TagArtists, albumArtists: models.TagAlbumArtists, display: true, multiValue: true, separator: " / ",
+		releaseDate: true, date: dateFull, originalDate: true,
+		clean: "false", explicit: "true",
+	},
+	models.TagProfileJellyfin: {
+		artists: tagArtist, albumArtists: tagAlbumArtist, multiValue: true, separator: "; ",
+		date: dateFull, year: true,
+		clean: "0", explicit: "1",
+	},
+	models.TagProfilePlex: {
+		artists: tagArtist, albumArtists: tagAlbumArtist, separator: "; ",
+		date: dateFull, year: true,
+		clean: "0", explicit: "1",
+	},
+	models.TagProfileKodi: {
+		artists: models.TagArtists, albumArtists: models.TagAlbumArtists, display: true, multiValue: true, separator: " / ",
+		date: dateFull, year: true, originalDate: true,
+		clean: "0", explicit: "1",
+	},
+	// taglib saves id3v2.4 frames, the profile sticks to what a v2.3 reader understands:
+	// single valued frames and a year only date
+	models.TagProfileID3v23: {
+		artists: tagArtist, albumArtists: tagAlbumArtist, separator: " / ",
+		date:  dateYear,
+		clean: "0", explicit: "1",
+	},
+}
+
+// listSeparators are the joins written by the profiles, split back when a file only holds the joined value
+var listSeparators = []string{"; ", " / "}
+
+var listTags = []string{models.TagGenre, models.TagComposer, models.TagLyricist}
+
+func getTagProfile(profile models.TagProfile) tagProfile {
+	if p, ok := tagProfiles[profile]; ok {
+		return p
+	}
+	return tagProfiles[models.TagProfileNavidrome]
+}
+
+// GetUserProfile returns the tagging profile of the user, navidrome when it cannot be read
+func GetUserProfile(userId uint) models.TagProfile {
+	user, err := repository.GetUserByID(userId)
+	if err != nil {
+		log.Println("metadata.GetUserProfile:", err)
+		return models.TagProfileNavidrome
+	}
+	if !user.TagProfile.Valid() {
+		return models.TagProfileNavidrome
+	}
+	return user.TagProfile
+}
+
+func (p tagProfile) list(values []string) []string {
+	if p.multiValue || len(values) <= 1 {
+		return values
+	}
+	return []string{strings.Join(values, p.separator)}
+}
+
+// ApplyProfile turns tags using the library keys into the layout of the profile, every key of a group
+// the profile does not use is erased so a file never keeps the values of a previous profile
+func ApplyProfile(profile models.TagProfile, tags map[string][]string) map[string][]string {
+	p := getTagProfile(profile)
+	out := make(map[string][]string, len(tags))
+	for key, values := range tags {
+		out[key] = values
+	}
+
+	artistGroup := func(canonical string, key string, display string) {
+		values, ok := tags[canonical]
+		if !ok {
+			return
+		}
+		for _, variant := range []string{canonical, display} {
+			out[variant] = []string{}
+		}
+		if len(values) == 0 {
+			return
+		}
+		if p.display {
+			out[display] = []string{strings.Join(values, p.separator)}
+		}
+		out[key] = p.list(values)
+	}
+	artistGroup(models.TagArtists, p.artists, tagArtist)
+	artistGroup(models.TagAlbumArtists, p.albumArtists, tagAlbumArtist)
+
+	if values, ok := tags[models.TagReleaseDate]; ok {
+		for _, variant := range []string{models.TagReleaseDate, tagDate, tagYear, tagOriginalDate} {
+			out[variant] = []string{}
+		}
+		if len(values) > 0 && values[0] != "" {
+			date := values[0]
+			year := date[:min(len(date), 4)]
+			if p.releaseDate {
+				out[models.TagReleaseDate] = []string{date}
+			}
+			switch p.date {
+			case dateFull:
+				out[tagDate] = []string{date}
+			case dateYear:
+				out[tagDate] = []string{year}
+			}
+			if p.year {
+				out[tagYear] = []string{year}
+			}
+			if p.originalDate {
+				out[tagOriginalDate] = []string{date}
+			}
+		}
+	}
+
+	if values, ok := tags[models.TagExplicit]; ok && len(values) > 0 {
+		if ParseExplicit(values[0]) {
+			out[models.TagExplicit] = []string{p.explicit}
+		} else {
+			out[models.TagExplicit] = []string{p.clean}
+		}
+	}
+
+	for _, key := range listTags {
+		if values, ok := tags[key]; ok {
+			out[key] = p.list(values)
+		}
+	}
+	return out
+}
+
+// ParseExplicit reads the advisory value of any profile, "0" and "2" (clean) read as not explicit
+func ParseExplicit(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "4", "true", "explicit":
+		return true
+	default:
+		return false
+	}
+}
+
+func splitList(values []string) []string {
+	if len(values) != 1 {
+		return values
+	}
+	for _, separator := range listSeparators {
+		if strings.Contains(values[0], separator) {
+			parts := make([]string, 0)
+			for part := range strings.SplitSeq(values[0], separator) {
+				if part = strings.TrimSpace(part); part != "" {
+					parts = append(parts, part)
+				}
+			}
+			return parts
+		}
+	}
+	return values
+}
+
+// normalizeTags fills the library keys from the layout of any profile, so the library reads
+// the files the same way whatever profile wrote them
+func normalizeTags(tags map[string][]string) map[string][]string {
+	if tags == nil {
+		return tags
+	}
+	if _, ok := tags[models.TagArtists]; !ok {
+		if values, ok := tags[tagArtist]; ok {
+			tags[models.TagArtists] = splitList(values)
+		}
+	}
+	if _, ok := tags[models.TagAlbumArtists]; !ok {
+		if values, ok := tags[tagAlbumArtist]; ok {
+			tags[models.TagAlbumArtists] = splitList(values)
+		}
+	}
+	if _, ok := tags[models.TagReleaseDate]; !ok {
+		for _, key := range []string{tagDate, tagOriginalDate, tagYear} {
+			if values, ok := tags[key]; ok && len(values) > 0 {
+				tags[models.TagReleaseDate] = values[:1]
+				break
+			}
+		}
+	}
+	if values, ok := tags[models.TagExplicit]; ok && len(values) > 0 {
+		if ParseExplicit(values[0]) {
+			tags[models.TagExplicit] = []string{"true"}
+		} else {
+			tags[models.TagExplicit] = []string{"false"}
+		}
+	}
+	for _, key := range listTags {
+		if values, ok := tags[key]; ok {
+			tags[key] = splitList(values)
+		}
+	}
+	return tags
+}
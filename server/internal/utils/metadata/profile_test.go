@@ -0,0 +1,138 @@
+package metadata
+
+import (
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/models"
+)
+
+// written drops the empty keys, taglib erases them from the file instead of saving an empty value
+func written(tags map[string][]string) map[string][]string {
+	maps.DeleteFunc(tags, func(_ string, values []string) bool {
+		return len(values) == 0
+	})
+	return tags
+}
+
+func TestApplyProfileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string][]string
+	}{
+		{
+			name: "full",
+			tags: map[string][]string{
+				models.TagTitle:        {"Song"},
+				models.TagArtists:      {"Band", "Other"},
+				models.TagAlbumArtists: {"Band"},
+				models.TagReleaseDate:  {"2011-05-02"},
+				models.TagExplicit:     {"true"},
+				models.TagGenre:        {"Rock", "Pop"},
+				models.TagComposer:     {"Writer"},
+			},
+		},
+		{
+			name: "clean single artist",
+			tags: map[string][]string{
+				models.TagTitle:        {"Song"},
+				models.TagArtists:      {"Band"},
+				models.TagAlbumArtists: {"Band", "Other"},
+				models.TagReleaseDate:  {"1999"},
+				models.TagExplicit:     {"false"},
+				models.TagLyricist:     {"Writer", "Poet"},
+			},
+		},
+		{
+			name: "no date nor artists",
+			tags: map[string][]string{
+				models.TagTitle: {"Song"},
+				models.TagAlbum: {"Record"},
+			},
+		},
+	}
+	for _, profile := range models.TagProfiles {
+		for _, tt := range tests {
+			t.Run(string(profile)+"/"+tt.name, func(t *testing.T) {
+				want := maps.Clone(tt.tags)
+				// a v2.3 file only keeps the year of the date
+				if date, ok := want[models.TagReleaseDate]; ok && profile == models.TagProfileID3v23 {
+					want[models.TagReleaseDate] = []string{date[0][:4]}
+				}
+
+				got := normalizeTags(written(ApplyProfile(profile, maps.Clone(tt.tags))))
+				for key, values := range want {
+					if !reflect.DeepEqual(got[key], values) {
+						t.Errorf("%s = %q, want %q", key, got[key], values)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestApplyProfileErasesOtherLayouts(t *testing.T) {
+	tags := map[string][]string{
+		models.TagArtists:      {"Band", "Other"},
+		models.TagAlbumArtists: {"Band"},
+		models.TagReleaseDate:  {"2011-05-02"},
+	}
+	keys := []string{models.TagArtists, models.TagAlbumArtists, tagArtist, tagAlbumArtist, models.TagReleaseDate, tagDate, tagYear, tagOriginalDate}
+
+	for _, profile := range models.TagProfiles {
+		t.Run(string(profile), func(t *testing.T) {
+			got := ApplyProfile(profile, tags)
+			for _, key := range keys {
+				if _, ok := got[key]; !ok {
+					t.Errorf("%s is left out, a previous profile would keep its value in the file", key)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfileExplicit(t *testing.T) {
+	tests := []struct {
+		profile   models.TagProfile
+		explicit  string
+		wantValue string
+	}{
+		{models.TagProfileNavidrome, "true", "true"},
+		{models.TagProfileNavidrome, "false", "false"},
+		{models.TagProfileJellyfin, "true", "1"},
+		{models.TagProfilePlex, "false", "0"},
+		{models.TagProfileKodi, "true", "1"},
+		{models.TagProfileID3v23, "false", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.profile)+"/"+tt.explicit, func(t *testing.T) {
+			got := ApplyProfile(tt.profile, map[string][]string{models.TagExplicit: {tt.explicit}})
+			if !reflect.DeepEqual(got[models.TagExplicit], []string{tt.wantValue}) {
+				t.Errorf("%s = %q, want %q", models.TagExplicit, got[models.TagExplicit], tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestParseExplicit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"true", true},
+		{"TRUE", true},
+		{"1", true},
+		{"4", true},
+		{"explicit", true},
+		{"false", false},
+		{"0", false},
+		{"2", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ParseExplicit(tt.value); got != tt.want {
+			t.Errorf("ParseExplicit(%q) = %t, want %t", tt.value, got, tt.want)
+		}
+	}
+}
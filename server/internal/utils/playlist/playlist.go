@@ -0,0 +1,216 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type Entry struct {
+	Title    string
+	Artist   string
+	Album    string
+	Isrc     string
+	Duration int
+	Path     string
+}
+
+type Format string
+
+const (
+	FormatM3U  Format = "m3u"
+	FormatM3U8 Format = "m3u8"
+	FormatXSPF Format = "xspf"
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// FormatOf returns the playlist format of a file name
+func FormatOf(name string) (Format, bool) {
+	switch Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")) {
+	case FormatM3U:
+		return FormatM3U, true
+	case FormatM3U8:
+		return FormatM3U8, true
+	case FormatXSPF:
+		return FormatXSPF, true
+	case FormatCSV:
+		return FormatCSV, true
+	case FormatJSON:
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+func Parse(format Format, r io.Reader) (string, []Entry, error) {
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return ParseM3U(r)
+	case FormatXSPF:
+		return ParseXSPF(r)
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatJSON:
+		return ParseJSON(r)
+	}
+	return "", nil, fmt.Errorf("playlist.Parse: unknown format %q", format)
+}
+
+func Write(format Format, w io.Writer, title string, entries []Entry) error {
+	switch format {
+	case FormatM3U, FormatM3U8:
+		return WriteM3U(w, title, entries)
+	case FormatXSPF:
+		return WriteXSPF(w, title, entries)
+	case FormatCSV:
+		return WriteCSV(w, entries)
+	case FormatJSON:
+		return WriteJSON(w, title, entries)
+	}
+	return fmt.Errorf("playlist.Write: unknown format %q", format)
+}
+
+// ParseM3U reads the #EXTINF and #PLAYLIST directives of an extended m3u, plain m3u only give the paths
+func ParseM3U(r io.Reader) (string, []Entry, error) {
+	var title string
+	entries := make([]Entry, 0)
+	var current Entry
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			duration, name, _ := strings.Cut(info, ",")
+			if fields := strings.Fields(duration); len(fields) > 0 {
+				if seconds, err := strconv.Atoi(fields[0]); err == nil && seconds > 0 {
+					current.Duration = seconds
+				}
+			}
+			if artist, songTitle, ok := strings.Cut(name, " - "); ok {
+				current.Artist = strings.TrimSpace(artist)
+				current.Title = strings.TrimSpace(songTitle)
+			} else {
+				current.Title = strings.TrimSpace(name)
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			current.Path = line
+			entries = append(entries, current)
+			current = Entry{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("playlist.ParseM3U: %w", err)
+	}
+	return title, entries, nil
+}
+
+func WriteM3U(w io.Writer, title string, entries []Entry) error {
+	var content strings.Builder
+	content.WriteString("#EXTM3U\n")
+	if title != "" {
+		content.WriteString("#PLAYLIST:" + title + "\n")
+	}
+	for _, entry := range entries {
+		if entry.Title != "" {
+			name := entry.Title
+			if entry.Artist != "" {
+				name = entry.Artist + " - " + name
+			}
+			duration := entry.Duration
+			if duration == 0 {
+				duration = -1
+			}
+			content.WriteString(fmt.Sprintf("#EXTINF:%d,%s\n", duration, name))
+		}
+		content.WriteString(filepath.ToSlash(entry.Path) + "\n")
+	}
+	if _, err := io.WriteString(w, content.String()); err != nil {
+		return fmt.Errorf("playlist.WriteM3U: %w", err)
+	}
+	return nil
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   string   `xml:"location,omitempty"`
+	Identifier []string `xml:"identifier,omitempty"`
+	Title      string   `xml:"title,omitempty"`
+	Creator    string   `xml:"creator,omitempty"`
+	Duration   int      `xml:"duration,omitempty"`
+}
+
+// ParseXSPF reads the tracks of a xspf playlist, the isrc is taken from an "isrc:" identifier
+func ParseXSPF(r io.Reader) (string, []Entry, error) {
+	var data xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&data); err != nil {
+		return "", nil, fmt.Errorf("playlist.ParseXSPF: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(data.Tracks))
+	for _, track := range data.Tracks {
+		entry := Entry{
+			Title:    strings.TrimSpace(track.Title),
+			Artist:   strings.TrimSpace(track.Creator),
+			Duration: track.Duration / 1000,
+		}
+		if location, err := url.Parse(strings.TrimSpace(track.Location)); err == nil && (location.Scheme == "file" || location.Scheme == "") {
+			entry.Path = location.Path
+		} else {
+			entry.Path = strings.TrimSpace(track.Location)
+		}
+		for _, identifier := range track.Identifier {
+			if isrc, ok := strings.CutPrefix(strings.TrimSpace(identifier), "isrc:"); ok {
+				entry.Isrc = isrc
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return strings.TrimSpace(data.Title), entries, nil
+}
+
+func WriteXSPF(w io.Writer, title string, entries []Entry) error {
+	data := xspfPlaylist{
+		Version: "1",
+		Title:   title,
+		Tracks:  make([]xspfTrack, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		track := xspfTrack{
+			Location: (&url.URL{Path: filepath.ToSlash(entry.Path)}).String(),
+			Title:    entry.Title,
+			Creator:  entry.Artist,
+			Duration: entry.Duration * 1000,
+		}
+		if entry.Isrc != "" {
+			track.Identifier = []string{"isrc:" + entry.Isrc}
+		}
+		data.Tracks = append(data.Tracks, track)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("playlist.WriteXSPF: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("playlist.WriteXSPF: %w", err)
+	}
+	return nil
+}
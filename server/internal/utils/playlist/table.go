@@ -0,0 +1,245 @@
+package playlist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// columns lists the names other services use in their exports for each field
+var columns = map[string][]string{
+	"title":    {"title", "track", "track name", "trackname", "song", "song name", "name"},
+	"artist":   {"artist", "artists", "artist name", "artist name(s)", "artistname", "creator"},
+	"album":    {"album", "album name", "albumname", "album title"},
+	"isrc":     {"isrc"},
+	"duration": {"duration", "duration (ms)", "duration_ms", "durationms", "length", "time"},
+	"path":     {"path", "location", "file"},
+}
+
+func fieldOf(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for field, names := range columns {
+		for _, known := range names {
+			if name == known {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// parseDuration reads seconds, milliseconds or m:ss, a value above a day is taken as milliseconds
+func parseDuration(value string, milliseconds bool) int {
+	value = strings.TrimSpace(value)
+	if minutes, seconds, ok := strings.Cut(value, ":"); ok {
+		m, err1 := strconv.Atoi(minutes)
+		s, err2 := strconv.Atoi(seconds)
+		if err1 != nil || err2 != nil {
+			return 0
+		}
+		return m*60 + s
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 {
+		return 0
+	}
+	if milliseconds || number > 86400 {
+		number /= 1000
+	}
+	return int(number + 0.5)
+}
+
+func setField(entry *Entry, field string, value string, milliseconds bool) {
+	value = strings.TrimSpace(value)
+	switch field {
+	case "title":
+		entry.Title = value
+	case "artist":
+		entry.Artist = value
+	case "album":
+		entry.Album = value
+	case "isrc":
+		entry.Isrc = strings.ToUpper(value)
+	case "duration":
+		entry.Duration = parseDuration(value, milliseconds)
+	case "path":
+		entry.Path = value
+	}
+}
+
+// ParseCSV reads a csv export with a header row, the delimiter is guessed between comma, semicolon and tab
+func ParseCSV(r io.Reader) (string, []Entry, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, fmt.Errorf("playlist.ParseCSV: %w", err)
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	header, _, _ := strings.Cut(text, "\n")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	for _, delimiter := range []rune{';', '\t'} {
+		if strings.Count(header, string(delimiter)) > strings.Count(header, string(reader.Comma)) {
+			reader.Comma = delimiter
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", nil, fmt.Errorf("playlist.ParseCSV: %w", err)
+	}
+	if len(records) == 0 {
+		return "", []Entry{}, nil
+	}
+
+	fields := make([]string, len(records[0]))
+	milliseconds := make([]bool, len(records[0]))
+	known := false
+	for i, name := range records[0] {
+		fields[i] = fieldOf(name)
+		milliseconds[i] = strings.Contains(strings.ToLower(name), "ms")
+		known = known || fields[i] != ""
+	}
+	if !known {
+		return "", nil, fmt.Errorf("playlist.ParseCSV: no known column in header")
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for _, record := range records[1:] {
+		var entry Entry
+		for i, value := range record {
+			if i < len(fields) && fields[i] != "" {
+				setField(&entry, fields[i], value, milliseconds[i])
+			}
+		}
+		if entry.Title != "" || entry.Isrc != "" || entry.Path != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return "", entries, nil
+}
+
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		if name, ok := v["name"]; ok {
+			return jsonString(name)
+		}
+	case []any:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			if name := jsonString(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// ParseJSON reads an array of tracks, either at the root or under a tracks, items or songs key,
+// a track nested under a track key is unwrapped
+func ParseJSON(r io.Reader) (string, []Entry, error) {
+	var data any
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return "", nil, fmt.Errorf("playlist.ParseJSON: %w", err)
+	}
+
+	var title string
+	tracks, ok := data.([]any)
+	if object, isObject := data.(map[string]any); isObject {
+		title = jsonString(object["name"])
+		if title == "" {
+			title = jsonString(object["title"])
+		}
+		for _, key := range []string{"tracks", "items", "songs"} {
+			if tracks, ok = object[key].([]any); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("playlist.ParseJSON: no track list found")
+	}
+
+	entries := make([]Entry, 0, len(tracks))
+	for _, item := range tracks {
+		object, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if track, ok := object["track"].(map[string]any); ok {
+			object = track
+		}
+
+		var entry Entry
+		for key, value := range object {
+			field := fieldOf(key)
+			if field == "" {
+				continue
+			}
+			if nested, ok := value.(map[string]any); ok && field == "isrc" {
+				value = nested["isrc"]
+			}
+			setField(&entry, field, jsonString(value), strings.Contains(strings.ToLower(key), "ms"))
+		}
+		if ids, ok := object["external_ids"].(map[string]any); ok && entry.Isrc == "" {
+			entry.Isrc = strings.ToUpper(jsonString(ids["isrc"]))
+		}
+		if entry.Title != "" || entry.Isrc != "" || entry.Path != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return title, entries, nil
+}
+
+func WriteCSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"title", "artist", "album", "isrc", "duration", "path"}); err != nil {
+		return fmt.Errorf("playlist.WriteCSV: %w", err)
+	}
+	for _, entry := range entries {
+		if err := writer.Write([]string{entry.Title, entry.Artist, entry.Album, entry.Isrc, strconv.Itoa(entry.Duration), entry.Path}); err != nil {
+			return fmt.Errorf("playlist.WriteCSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("playlist.WriteCSV: %w", err)
+	}
+	return nil
+}
+
+type jsonTrack struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album"`
+	Isrc     string `json:"isrc"`
+	Duration int    `json:"duration"`
+	Path     string `json:"path"`
+}
+
+func WriteJSON(w io.Writer, title string, entries []Entry) error {
+	data := struct {
+		Name   string      `json:"name"`
+		Tracks []jsonTrack `json:"tracks"`
+	}{Name: title, Tracks: make([]jsonTrack, 0, len(entries))}
+	for _, entry := range entries {
+		data.Tracks = append(data.Tracks, jsonTrack(entry))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("playlist.WriteJSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package playlist
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Entry
+		wantErr bool
+	}{
+		{
+			name:    "comma",
+			content: "Title,Artist,Album,ISRC,Duration\nSong,Band,Record,usabc1234567,215\n",
+			want:    []Entry{{Title: "Song", Artist: "Band", Album: "Record", Isrc: "USABC1234567", Duration: 215}},
+		},
+		{
+			name:    "semicolon",
+			content: "Track Name;Artist Name(s);Album Name;Duration (ms)\nSong;Band, Other;Record;215000\n",
+			want:    []Entry{{Title: "Song", Artist: "Band, Other", Album: "Record", Duration: 215}},
+		},
+		{
+			name:    "tab",
+			content: "title\tartist\tlength\nSong\tBand\t3:35\n",
+			want:    []Entry{{Title: "Song", Artist: "Band", Duration: 215}},
+		},
+		{
+			name:    "comma in quoted field with semicolons",
+			content: "title,artist\n\"Song; Live\",Band\n",
+			want:    []Entry{{Title: "Song; Live", Artist: "Band"}},
+		},
+		{
+			name:    "byte order mark",
+			content: "\ufefftitle,artist\nSong,Band\n",
+			want:    []Entry{{Title: "Song", Artist: "Band"}},
+		},
+		{
+			name:    "milliseconds from the header",
+			content: "title,duration_ms\nSong,5000\n",
+			want:    []Entry{{Title: "Song", Duration: 5}},
+		},
+		{
+			name:    "milliseconds from the value",
+			content: "title,duration\nSong,215000\n",
+			want:    []Entry{{Title: "Song", Duration: 215}},
+		},
+		{
+			name:    "seconds",
+			content: "title,duration\nSong,215.4\n",
+			want:    []Entry{{Title: "Song", Duration: 215}},
+		},
+		{
+			name:    "rows without title, isrc or path are skipped",
+			content: "title,artist,isrc\n,Band,\nSong,Band,\n",
+			want:    []Entry{{Title: "Song", Artist: "Band"}},
+		},
+		{
+			name:    "header only",
+			content: "title,artist\n",
+			want:    []Entry{},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    []Entry{},
+		},
+		{
+			name:    "no known column",
+			content: "foo,bar\n1,2\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := ParseCSV(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSV() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
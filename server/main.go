@@ -6,8 +6,11 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/config"
+	database "github.com/DimitriLaPoudre/MusicShack/server/internal/db"
 	_ "github.com/DimitriLaPoudre/MusicShack/server/internal/plugins"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/routes"
+	"github.com/DimitriLaPoudre/MusicShack/server/internal/services"
 	"github.com/DimitriLaPoudre/MusicShack/server/internal/utils/autofetch"
 )
 
@@ -15,7 +18,14 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	config.Load()
+	database.Connect()
+	services.LoadSettings()
+
 	cron := autofetch.AutoFetch(ctx)
+	if _, err := cron.AddFunc("@hourly", services.PurgeExpiredTrash); err != nil {
+		log.Println("main:", err)
+	}
 	r := routes.SetupRouters()
 	defer r.Close()
 	if err := r.RunWithContext(ctx); err != nil && err != context.Canceled {